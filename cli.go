@@ -23,6 +23,18 @@ import (
 )
 
 var StartScript = flag.String("script", "/home/bbaa/Minecraft/TestNeoforgeServer/run.sh", "start")
+var CommandTransport = flag.String("transport", core.CommandTransportStdio, "command transport: stdio or rcon")
+var RconAddress = flag.String("rcon", "127.0.0.1:25575", "rcon address")
+var RconPassword = flag.String("rcon-password", "", "rcon password")
+var CommandRate = flag.Int("command-rate", 0, "max commands per second sent to server, 0 for unlimited")
+var DataDir = flag.String("data", "data", "plugin data directory")
+var APIListen = flag.String("api", "", "http api listen address, empty to disable")
+var APIToken = flag.String("api-token", "", "http api bearer token")
+var AutoRestart = flag.Bool("auto-restart", false, "restart minecraft server after crash")
+var RestartMax = flag.Int("restart-max", 3, "max restarts within restart-window before giving up")
+var RestartWindow = flag.Duration("restart-window", 10*time.Minute, "window for counting restarts")
+var RestartCooldown = flag.Duration("restart-cooldown", 10*time.Second, "delay before the first restart, doubled on each retry")
+var RestartWebhook = flag.String("restart-webhook", "", "discord compatible webhook notified on restart")
 
 func main() {
 	flag.Parse()
@@ -40,7 +52,21 @@ func main() {
 }
 
 func createGameManager() error {
-	minecraftManagerClient := &core.MinecraftPluginManager{StartScript: *StartScript}
+	minecraftManagerClient := &core.MinecraftPluginManager{
+		StartScript:      *StartScript,
+		CommandTransport: *CommandTransport,
+		RconAddress:      *RconAddress,
+		RconPassword:     *RconPassword,
+		CommandRate:      *CommandRate,
+		DataDir:          *DataDir,
+		Supervisor: core.MinecraftSupervisor{
+			AutoRestart: *AutoRestart,
+			MaxRestarts: *RestartMax,
+			Window:      *RestartWindow,
+			Cooldown:    *RestartCooldown,
+			Webhook:     *RestartWebhook,
+		},
+	}
 	err := minecraftManagerClient.Dial("127.0.0.1:12345")
 	if err != nil {
 		return err
@@ -50,5 +76,8 @@ func createGameManager() error {
 	minecraftManagerClient.RegisterPlugin(&plugins.BackPlugin{})
 	minecraftManagerClient.RegisterPlugin(&plugins.BackupPlugin{Source: "/home/bbaa/Minecraft/TestNeoforgeServer/world", Dest: "/home/bbaa/Minecraft/Backup/"})
 	minecraftManagerClient.RegisterPlugin(&plugins.StatusPlugin{})
+	if *APIListen != "" {
+		minecraftManagerClient.RegisterPlugin(&plugins.APIPlugin{Listen: *APIListen, Token: *APIToken})
+	}
 	return nil
 }
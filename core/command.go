@@ -15,14 +15,17 @@
 package core
 
 import (
+	"fmt"
 	"regexp"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/manager"
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/rcon"
 	"github.com/fatih/color"
 )
 
@@ -38,6 +41,11 @@ type MinecraftCommandProcessor struct {
 	receiverLock     sync.RWMutex
 	index            uint64
 	cleanSignal      chan struct{}
+	rcon             *rcon.Client
+	sentinelTimeouts atomic.Int32
+	pendingSentinels []string // 已超时但尚未收到回显的结束标记, 仅由 Worker 访问
+	rate             int
+	nextSlot         time.Time
 }
 
 func (mc *MinecraftCommandProcessor) Println(a ...any) (int, error) {
@@ -49,13 +57,83 @@ var UnknownCommand = regexp.MustCompile("Unknown or incomplete command")
 var SkipWaitCommand []string = []string{"tellraw"}
 var WaitForRegexCommand map[string]*regexp.Regexp = map[string]*regexp.Regexp{"save-all": regexp.MustCompile("Saved"), "testServerReady": UnknownCommand, "list": regexp.MustCompile("players online")}
 
+// 等待结束标记的最长时间, 超时后退回按输出间隔判断命令结束
+var CommandSentinelTimeout = 5 * time.Second
+
+// 连续超时达到该次数后停用结束标记, 服务器重启时恢复
+const CommandSentinelMaxTimeouts = 3
+
+// 最多记录的迟到结束标记数
+const commandPendingSentinels = 16
+
+// 在命令后追加一条未知命令作为结束标记, 服务端回显其最后 10 个字符, 收到回显即代表前一条命令的输出已全部输出
+// Paper/Spigot 不回显未知命令, 其他服务端连续超时 CommandSentinelMaxTimeouts 次后停用
+func (mc *MinecraftCommandProcessor) sentinel() string {
+	return fmt.Sprintf("mpd%07d", mc.index%10000000)
+}
+
+func (mc *MinecraftCommandProcessor) sentinelSupported() bool {
+	switch mc.managerClient.ServerInfo().Flavor {
+	case pluginabi.ServerFlavorPaper, pluginabi.ServerFlavorSpigot:
+		return false
+	}
+	return mc.sentinelTimeouts.Load() < CommandSentinelMaxTimeouts
+}
+
+// 超时的结束标记可能在之后的命令执行期间回显, 需从其输出中去除
+func (mc *MinecraftCommandProcessor) takePendingSentinel(message string) bool {
+	for i, sentinel := range mc.pendingSentinels {
+		if strings.HasSuffix(message, sentinel+"<--[HERE]") {
+			mc.pendingSentinels = slices.Delete(mc.pendingSentinels, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
 func (mc *MinecraftCommandProcessor) RunCommand(command string) (response string) {
+	return <-mc.RunCommandAsync(command)
+}
+
+// 命令仍由 Worker 按入队顺序逐条执行, 输出匹配不受并发调用影响
+func (mc *MinecraftCommandProcessor) RunCommandAsync(command string) <-chan string {
 	resp := make(chan string, 1)
 	mc.queue <- &MinecraftCommandRequest{
 		command:  command,
 		response: resp,
 	}
-	return <-resp
+	return resp
+}
+
+// 排队中的命令数, 不含正在执行的命令
+func (mc *MinecraftCommandProcessor) QueueDepth() int {
+	return len(mc.queue)
+}
+
+// 按每秒命令数限速, 多行命令按行数计算
+func (mc *MinecraftCommandProcessor) throttle(command string) {
+	if mc.rate <= 0 {
+		return
+	}
+	now := time.Now()
+	if mc.nextSlot.After(now) {
+		time.Sleep(mc.nextSlot.Sub(now))
+		now = mc.nextSlot
+	}
+	lines := strings.Count(command, "\n") + 1
+	mc.nextSlot = now.Add(time.Duration(lines) * time.Second / time.Duration(mc.rate))
+}
+
+// 超时后命令仍会在队列中执行, 只是结果被丢弃
+func (mc *MinecraftCommandProcessor) RunCommandTimeout(command string, d time.Duration) (string, error) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case response := <-mc.RunCommandAsync(command):
+		return response, nil
+	case <-timer.C:
+		return "", fmt.Errorf("command %q timeout after %s", command, d)
+	}
 }
 
 func (mc *MinecraftCommandProcessor) commandResponeProcessor(logText string, _ bool) {
@@ -70,8 +148,34 @@ func (mc *MinecraftCommandProcessor) commandResponeProcessor(logText string, _ b
 	}
 }
 
+func (mc *MinecraftCommandProcessor) runRconCommand(cmd *MinecraftCommandRequest) {
+	command := strings.TrimLeft(cmd.command, "/")
+	mc.Println(color.YellowString("正在通过 RCON 执行命令["), color.GreenString("%d", mc.index), color.YellowString("]: "), color.RedString(command), color.YellowString(" 队列中剩余: "), color.RedString("%d", len(mc.queue)))
+	// RCON 每个请求只执行一条命令, 多行命令逐行发送, 输出按行拼接, 与 stdio 一致
+	responses := []string{}
+	for _, line := range strings.Split(command, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		response, err := mc.rcon.Command(strings.TrimLeft(line, "/"))
+		if err != nil {
+			mc.Println(color.RedString("RCON 命令执行失败: "), color.MagentaString(err.Error()))
+		}
+		if response = strings.TrimRight(response, "\n"); response != "" {
+			responses = append(responses, response)
+		}
+	}
+	cmd.response <- strings.Join(responses, "\n")
+	mc.index++
+}
+
 func (mc *MinecraftCommandProcessor) Worker() {
 	for cmd := range mc.queue {
+		mc.throttle(cmd.command)
+		if mc.rcon != nil {
+			mc.runRconCommand(cmd)
+			continue
+		}
 		var waitRegex *regexp.Regexp
 		var isWaitRegex bool
 		var responseReceiver chan string
@@ -102,11 +206,20 @@ func (mc *MinecraftCommandProcessor) Worker() {
 			mc.index++
 			continue
 		}
+		sentinel := ""
+		if _, isWaitRegex = WaitForRegexCommand[command]; !isWaitRegex && mc.sentinelSupported() {
+			sentinel = mc.sentinel()
+			mc.managerClient.Write(&manager.WriteRequest{Id: mc.index, Content: sentinel})
+		}
 		renewLockTicker := time.NewTicker(5 * time.Second)
 		var endCommandTimer *time.Timer
 		var endCommandChannel <-chan time.Time = nil
 		if waitRegex, isWaitRegex = WaitForRegexCommand[command]; !isWaitRegex {
-			endCommandTimer = time.NewTimer(100 * time.Millisecond)
+			if sentinel != "" {
+				endCommandTimer = time.NewTimer(CommandSentinelTimeout)
+			} else {
+				endCommandTimer = time.NewTimer(100 * time.Millisecond)
+			}
 			endCommandChannel = endCommandTimer.C
 		}
 
@@ -120,7 +233,7 @@ func (mc *MinecraftCommandProcessor) Worker() {
 					continue
 				}
 				queue := len(responseReceiver)
-				if !isWaitRegex {
+				if !isWaitRegex && sentinel == "" {
 					if !endCommandTimer.Stop() {
 						<-endCommandTimer.C
 					}
@@ -128,6 +241,19 @@ func (mc *MinecraftCommandProcessor) Worker() {
 				}
 				match := DedicatedServerMessage.FindStringSubmatch(line)
 				if len(match) == 2 {
+					ended := sentinel != "" && strings.HasSuffix(match[1], sentinel+"<--[HERE]")
+					if ended || mc.takePendingSentinel(match[1]) {
+						// 去掉结束标记产生的 Unknown or incomplete command
+						if len(commandBuffer) > 0 && UnknownCommand.MatchString(commandBuffer[len(commandBuffer)-1]) {
+							commandBuffer = commandBuffer[:len(commandBuffer)-1]
+						}
+						mc.sentinelTimeouts.Store(0)
+						if !ended {
+							continue
+						}
+						mc.Println(color.BlueString("命令执行结束"), color.YellowString("["), color.GreenString("%d", mc.index), color.YellowString("]: "), color.RedString(cmd.command))
+						break cmdReceiver
+					}
 					commandBuffer = append(commandBuffer, match[1])
 					if !isWaitRegex {
 						mc.Println(color.YellowString("将命令["), color.GreenString("%d", mc.index), color.YellowString("]: "), color.RedString(cmd.command), color.YellowString(" 的输出储存为: "), color.CyanString(match[1]))
@@ -139,6 +265,17 @@ func (mc *MinecraftCommandProcessor) Worker() {
 					}
 				}
 			case <-endCommandChannel:
+				if sentinel != "" {
+					mc.pendingSentinels = append(mc.pendingSentinels, sentinel)
+					if len(mc.pendingSentinels) > commandPendingSentinels {
+						mc.pendingSentinels = mc.pendingSentinels[1:]
+					}
+					if mc.sentinelTimeouts.Add(1) >= CommandSentinelMaxTimeouts {
+						mc.Println(color.RedString("连续未收到命令结束标记, 服务器重启前改为按输出间隔判断命令结束"))
+					} else {
+						mc.Println(color.RedString("未收到命令结束标记, 其回显将在之后的输出中忽略"))
+					}
+				}
 				mc.Println(color.BlueString("命令执行结束"), color.YellowString("["), color.GreenString("%d", mc.index), color.YellowString("]: "), color.RedString(cmd.command))
 				break cmdReceiver
 			case <-cleanSignal:
@@ -162,6 +299,19 @@ func (mc *MinecraftCommandProcessor) Worker() {
 
 func (mc *MinecraftCommandProcessor) Init(mpm pluginabi.PluginManager) error {
 	mc.managerClient = mpm.(*MinecraftPluginManager)
+	switch mc.managerClient.CommandTransport {
+	case "", CommandTransportStdio:
+	case CommandTransportRcon:
+		// 首次执行命令时才连接, 服务器可能尚未启动
+		mc.rcon = &rcon.Client{Address: mc.managerClient.RconAddress, Password: mc.managerClient.RconPassword, Timeout: 10 * time.Second}
+		mc.Println(color.YellowString("命令将通过 RCON 发送到: "), color.BlueString(mc.rcon.Address))
+	default:
+		mc.Println(color.RedString("未知的命令传输方式: "), color.MagentaString(mc.managerClient.CommandTransport), color.RedString(", 使用 stdio"))
+	}
+	mc.rate = mc.managerClient.CommandRate
+	if mc.rate > 0 {
+		mc.Println(color.YellowString("命令发送速率限制为每秒 "), color.GreenString("%d", mc.rate), color.YellowString(" 条"))
+	}
 	mpm.RegisterLogProcesser(mc, mc.commandResponeProcessor)
 	mc.queue = make(chan *MinecraftCommandRequest, 16384)
 	go mc.Worker()
@@ -177,6 +327,7 @@ func (mc *MinecraftCommandProcessor) DisplayName() string {
 }
 
 func (mc *MinecraftCommandProcessor) Start() {
+	mc.sentinelTimeouts.Store(0)
 }
 
 func (mc *MinecraftCommandProcessor) Pause() {
@@ -17,6 +17,8 @@ package core
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"slices"
@@ -36,12 +38,16 @@ import (
 type GameManagerMessageBus struct {
 	client   manager.Manager_MessageClient
 	channels []chan *manager.MessageResponse
+	owners   map[chan *manager.MessageResponse]string // 日志处理器所属插件, 卸载插件时移除
 	lock     sync.RWMutex
 }
 
 type PluginManager struct {
-	started bool
-	plugin  pluginabi.Plugin
+	started  bool
+	disabled bool
+	core     bool
+	plugin   pluginabi.Plugin
+	lock     sync.Mutex
 }
 
 func (pm *PluginManager) Init(mpm *MinecraftPluginManager) error {
@@ -52,6 +58,9 @@ func (pm *PluginManager) Init(mpm *MinecraftPluginManager) error {
 		return err
 	}
 	mpm.kPrintln(color.YellowString("插件 "), color.BlueString(pm.plugin.DisplayName()), color.GreenString(" 加载成功"))
+	mpm.pluginLock.Lock()
+	mpm.initOrder = append(mpm.initOrder, pm)
+	mpm.pluginLock.Unlock()
 	if mpm.minecraftState == manager.MinecraftState_running {
 		pm.Start()
 	}
@@ -59,28 +68,57 @@ func (pm *PluginManager) Init(mpm *MinecraftPluginManager) error {
 }
 
 func (pm *PluginManager) Start() {
-	if pm.plugin != nil && !pm.started {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+	pm.start()
+}
+
+func (pm *PluginManager) start() {
+	if pm.plugin != nil && !pm.started && !pm.disabled {
 		pm.started = true
 		pm.plugin.Start()
 	}
 }
 
 func (pm *PluginManager) Pause() {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+	pm.pause()
+}
+
+func (pm *PluginManager) pause() {
 	if pm.plugin != nil && pm.started {
 		pm.started = false
 		pm.plugin.Pause()
+		if tc, ok := pm.plugin.(pluginabi.TaskCanceller); ok {
+			tc.CancelAllTasks()
+		}
 	}
 }
 
 var (
 	errGameServerStopped     = fmt.Errorf("minecraft game stop")
+	errGameServerCrashed     = fmt.Errorf("minecraft game crash")
 	errGrpcChannelDisconnect = fmt.Errorf("grpc disconnected")
 )
 
+const (
+	CommandTransportStdio = "stdio"
+	CommandTransportRcon  = "rcon"
+)
+
 type MinecraftPluginManager struct {
 	Repl             *REPLPlugin
 	Address          string
 	StartScript      string
+	CommandTransport string // stdio (默认) 或 rcon
+	RconAddress      string
+	RconPassword     string
+	CommandRate      int    // 每秒最多发送的命令数, 0 为不限制
+	DataDir          string // 插件数据目录, 默认 data
+	Supervisor       MinecraftSupervisor
+	serverInfo       serverInfoDetector
+	structuredLog    structuredLogBus
 	ClientInfo       *manager.Client
 	client           manager.ManagerClient
 	context          context.Context
@@ -89,13 +127,36 @@ type MinecraftPluginManager struct {
 	commandProcessor *MinecraftCommandProcessor
 	plugins          map[string]*PluginManager
 	delayinitPlugins []*PluginManager
+	initOrder        []*PluginManager
+	shutdownOnce     sync.Once
+	signalOnce       sync.Once
 	pluginLock       sync.RWMutex
 	minecraftState   manager.MinecraftState
+	eventBus         EventBus
 }
 
 func (mpm *MinecraftPluginManager) RunCommand(cmd string) string {
 	return mpm.commandProcessor.RunCommand(cmd)
 }
+
+func (mpm *MinecraftPluginManager) RunCommandAsync(cmd string) <-chan string {
+	return mpm.commandProcessor.RunCommandAsync(cmd)
+}
+
+func (mpm *MinecraftPluginManager) RunCommandTimeout(cmd string, d time.Duration) (string, error) {
+	return mpm.commandProcessor.RunCommandTimeout(cmd, d)
+}
+
+func (mpm *MinecraftPluginManager) DataPath(name string) string {
+	if mpm.DataDir == "" {
+		return filepath.Join("data", name)
+	}
+	return filepath.Join(mpm.DataDir, name)
+}
+
+func (mpm *MinecraftPluginManager) CommandQueueDepth() int {
+	return mpm.commandProcessor.QueueDepth()
+}
 func (mpm *MinecraftPluginManager) Lock(opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	if mpm.ClientInfo == nil {
 		return nil, errGrpcChannelDisconnect
@@ -202,8 +263,20 @@ func (mpm *MinecraftPluginManager) registerLogProcesser(context pluginabi.Plugin
 	}
 	mpm.kPrintln(color.YellowString("插件 "), color.BlueString(pluginName), color.YellowString(" 注册了一个日志处理器: "), color.GreenString(GetFunctionName(process)))
 	channel = mpm.RegisterManagerMessageChannel(skipRegister)
+	if context != nil {
+		mpm.messageBus.lock.Lock()
+		if mpm.messageBus.owners == nil {
+			mpm.messageBus.owners = make(map[chan *manager.MessageResponse]string)
+		}
+		mpm.messageBus.owners[channel] = context.Name()
+		mpm.messageBus.lock.Unlock()
+	}
 	go func() {
 		for msg := range channel {
+			// 被禁用的插件不处理日志
+			if context != nil && mpm.PluginDisabled(context.Name()) {
+				continue
+			}
 			switch msg.Type {
 			case "stdout":
 				process(msg.Content, msg.Locked)
@@ -240,6 +313,21 @@ func (mpm *MinecraftPluginManager) UnRegisterManagerMessageChannel(channel chan
 	if idx >= 0 {
 		mpm.messageBus.channels = slices.Delete(mpm.messageBus.channels, idx, idx+1)
 	}
+	delete(mpm.messageBus.owners, channel)
+}
+
+// 移除并关闭插件注册的日志处理器
+func (mpm *MinecraftPluginManager) unregisterLogProcessers(pluginName string) {
+	mpm.messageBus.lock.Lock()
+	defer mpm.messageBus.lock.Unlock()
+	for channel, owner := range mpm.messageBus.owners {
+		if owner != pluginName {
+			continue
+		}
+		delete(mpm.messageBus.owners, channel)
+		mpm.messageBus.channels = slices.DeleteFunc(mpm.messageBus.channels, func(c chan *manager.MessageResponse) bool { return c == channel })
+		close(channel)
+	}
 }
 
 func (mpm *MinecraftPluginManager) getStatus() (status *manager.StatusResponse, err error) {
@@ -302,6 +390,34 @@ func (mpm *MinecraftPluginManager) GetPlugin(pluginName string) pluginabi.Plugin
 	return nil
 }
 
+func (mpm *MinecraftPluginManager) UnloadPlugin(pluginName string) error {
+	mpm.pluginLock.Lock()
+	pm, ok := mpm.plugins[pluginName]
+	if ok {
+		delete(mpm.plugins, pluginName)
+		mpm.initOrder = slices.DeleteFunc(mpm.initOrder, func(item *PluginManager) bool { return item == pm })
+	}
+	mpm.pluginLock.Unlock()
+	if !ok {
+		return fmt.Errorf("plugin not found")
+	}
+	pm.Pause()
+	mpm.eventBus.unsubscribe(pm.plugin.Name())
+	mpm.unregisterLogProcessers(pm.plugin.Name())
+	mpm.structuredLog.unregister(pm.plugin.Name())
+	if sc, err := plugin.Get[*plugin.ScoreboardCore](mpm); err == nil && mpm.minecraftState == manager.MinecraftState_running {
+		sc.RemoveAllObjectives(pm.plugin)
+	}
+	if bc, err := plugin.Get[*plugin.BossbarCore](mpm); err == nil && mpm.minecraftState == manager.MinecraftState_running {
+		bc.RemoveAllBossbars(pm.plugin)
+	}
+	if tc, err := plugin.Get[*plugin.TeamCore](mpm); err == nil && mpm.minecraftState == manager.MinecraftState_running {
+		tc.RemoveAllTeams(pm.plugin)
+	}
+	mpm.kPrintln(color.YellowString("插件 "), color.BlueString(pm.plugin.DisplayName()), color.YellowString(" 已卸载"))
+	return nil
+}
+
 func (mpm *MinecraftPluginManager) pluginStart() {
 	mpm.pluginLock.RLock()
 	for _, plugin := range mpm.plugins {
@@ -350,6 +466,7 @@ func (mpm *MinecraftPluginManager) StartMinecraft() (err error) {
 		close(minecraftStartingLog)
 	}
 	mpm.minecraftState = manager.MinecraftState_running
+	mpm.finishServerInfo()
 	mpm.kPrintln(color.YellowString("通知插件 Minecraft 启动完成"))
 	mpm.pluginStart()
 	return nil
@@ -373,17 +490,30 @@ func (mpm *MinecraftPluginManager) initPlugin() (err error) {
 	mpm.kPrintln(color.YellowString("正在注册命令处理器"))
 	mpm.commandProcessor = &MinecraftCommandProcessor{}
 	mpm.RegisterPlugin(mpm.commandProcessor)
+	mpm.RegisterLogProcesser(&pluginabi.PluginNameWrapper{PluginName: "ServerInfo", PluginDisplayName: "服务端信息"}, mpm.serverInfoProcesser)
 	mpm.kPrintln(color.YellowString("正在加载内置插件"))
+	if err := os.MkdirAll(mpm.DataPath(""), 0755); err != nil {
+		mpm.kPrintln(color.RedString("创建数据目录失败: "), color.MagentaString(err.Error()))
+	}
 	// repl
 	mpm.Repl = &REPLPlugin{}
 	mpm.RegisterPlugin(mpm.Repl)
 
+	// 按注册顺序初始化, SimpleCommand 需最先初始化, 其他核心插件才能在 Init 中注册命令
+	mpm.registerPlugin(&plugin.SimpleCommand{})
 	mpm.registerPlugin(&plugin.ScoreboardCore{})
 	mpm.registerPlugin(&plugin.TellrawManager{})
+	mpm.registerPlugin(&plugin.BossbarCore{})
+	mpm.registerPlugin(&plugin.TeamCore{})
 	mpm.registerPlugin(&plugin.PlayerInfo{})
+	mpm.registerPlugin(&plugin.AreaCore{})
 	mpm.registerPlugin(&plugin.TeleportCore{})
-	mpm.registerPlugin(&plugin.SimpleCommand{})
 	mpm.initDelayedPlugin()
+	mpm.pluginLock.RLock()
+	for _, pm := range mpm.plugins {
+		pm.core = true
+	}
+	mpm.pluginLock.RUnlock()
 	return
 }
 
@@ -407,6 +537,10 @@ func (mpm *MinecraftPluginManager) monitorGameStopWorker(message chan *manager.M
 			switch msg.Content {
 			case "GameServerStop":
 				mpm.errBus <- errGameServerStopped
+			case "GameServerCrash":
+				mpm.errBus <- errGameServerCrashed
+			case "StartGameServer":
+				mpm.resetServerInfo()
 			}
 		}
 	}
@@ -416,8 +550,11 @@ func (mpm *MinecraftPluginManager) errorHandler() {
 	for err := range mpm.errBus {
 		switch err {
 		case errGameServerStopped:
+			mpm.minecraftState = manager.MinecraftState_stopped
 			mpm.kPrintln(color.RedString("服务器关闭，请求停止插件"))
 			mpm.pluginPause()
+		case errGameServerCrashed:
+			mpm.handleCrash()
 		case errGrpcChannelDisconnect:
 			mpm.ClientInfo = nil
 			mpm.pluginPause()
@@ -471,6 +608,7 @@ func (mpm *MinecraftPluginManager) init() (err error) {
 		mpm.plugins = make(map[string]*PluginManager)
 	}
 	mpm.context = context.Background()
+	mpm.signalOnce.Do(mpm.handleSignal)
 	return
 }
 
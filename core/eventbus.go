@@ -0,0 +1,91 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"sync"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"github.com/fatih/color"
+)
+
+type eventSubscription struct {
+	plugin  string
+	handler func(payload any)
+}
+
+type EventBus struct {
+	handlers map[string][]eventSubscription
+	lock     sync.RWMutex
+}
+
+func (eb *EventBus) subscribe(plugin string, topic string, handler func(payload any)) {
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+	if eb.handlers == nil {
+		eb.handlers = make(map[string][]eventSubscription)
+	}
+	eb.handlers[topic] = append(eb.handlers[topic], eventSubscription{plugin: plugin, handler: handler})
+}
+
+// 未指定 topic 时取消该插件的全部订阅
+func (eb *EventBus) unsubscribe(plugin string, topics ...string) {
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+	for topic, subscriptions := range eb.handlers {
+		if len(topics) > 0 && !slices.Contains(topics, topic) {
+			continue
+		}
+		eb.handlers[topic] = slices.DeleteFunc(slices.Clone(subscriptions), func(s eventSubscription) bool {
+			return s.plugin == plugin
+		})
+	}
+}
+
+func (eb *EventBus) subscribers(topic string) []eventSubscription {
+	eb.lock.RLock()
+	defer eb.lock.RUnlock()
+	return eb.handlers[topic]
+}
+
+func (mpm *MinecraftPluginManager) Subscribe(context pluginabi.PluginName, topic string, handler func(payload any)) {
+	mpm.eventBus.subscribe(context.Name(), topic, handler)
+}
+
+func (mpm *MinecraftPluginManager) Unsubscribe(context pluginabi.PluginName, topics ...string) {
+	mpm.eventBus.unsubscribe(context.Name(), topics...)
+}
+
+func (mpm *MinecraftPluginManager) Publish(topic string, payload any) {
+	for _, subscription := range mpm.eventBus.subscribers(topic) {
+		if mpm.PluginDisabled(subscription.plugin) {
+			continue
+		}
+		go mpm.deliverEvent(topic, subscription.handler, payload)
+	}
+}
+
+func (mpm *MinecraftPluginManager) deliverEvent(topic string, handler func(payload any), payload any) {
+	// 单个订阅者崩溃不影响其他插件
+	defer func() {
+		if err := recover(); err != nil {
+			mpm.kPrintln(color.RedString("事件 "), color.BlueString(topic), color.RedString(" 处理失败: "), color.MagentaString(fmt.Sprint(err)), "\n", string(debug.Stack()))
+		}
+	}()
+	handler(payload)
+}
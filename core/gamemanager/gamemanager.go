@@ -50,9 +50,10 @@ func Println(a ...any) (n int, err error) {
 }
 
 type MinecraftVistor struct {
-	process *exec.Cmd
-	pty     io.ReadWriteCloser
-	state   manager.MinecraftState
+	process       *exec.Cmd
+	pty           io.ReadWriteCloser
+	state         manager.MinecraftState
+	stopRequested atomic.Bool
 }
 
 type WriteLock struct {
@@ -257,9 +258,19 @@ func (ms *ManagerServer) UnregisterForwardChannel(channel *ForwardChannel) {
 
 func (ms *ManagerServer) stopDetect() {
 	if ms.minecraftInstance.process != nil {
-		ms.minecraftInstance.process.Process.Wait()
+		state, err := ms.minecraftInstance.process.Process.Wait()
 		ms.minecraftInstance.pty.Close()
 		ms.minecraftInstance.state = manager.MinecraftState_stopped
+		exitCode := 0
+		if err == nil {
+			exitCode = state.ExitCode()
+		}
+		// 非客户端请求且退出码非 0 视为崩溃
+		if exitCode != 0 && !ms.minecraftInstance.stopRequested.Load() {
+			ms.messageBus <- &manager.MessageResponse{Type: "StateChange", Content: "GameServerCrash"}
+			Println(color.RedString("服务器异常退出, 退出码: "), color.MagentaString("%d", exitCode))
+			return
+		}
 		ms.messageBus <- &manager.MessageResponse{Type: "StateChange", Content: "GameServerStop"}
 		Println(color.RedString("服务器关闭"))
 	}
@@ -270,6 +281,7 @@ func (ms *ManagerServer) Start(ctx context.Context, req *manager.StartRequest) (
 		return nil, ErrMinecraftAlreadyRunning
 	}
 	ms.minecraftInstance.state = manager.MinecraftState_running
+	ms.minecraftInstance.stopRequested.Store(false)
 	Println(color.YellowString("客户端["), color.GreenString("%d", req.Client.Id), color.YellowString("]: 启动服务器: "), color.MagentaString(req.Path))
 	cmd := exec.Command(filepath.Clean(req.Path))
 
@@ -436,6 +448,7 @@ func (ms *ManagerServer) Stop(ctx context.Context, client *manager.Client) (c *e
 		}
 	}()
 	if ms.minecraftInstance.state == manager.MinecraftState_running {
+		ms.minecraftInstance.stopRequested.Store(true)
 		ms.minecraftInstance.pty.Write([]byte("stop\n"))
 		ms.minecraftInstance.process.Process.Wait()
 		ms.minecraftInstance.pty.Close()
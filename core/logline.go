@@ -0,0 +1,119 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"github.com/fatih/color"
+)
+
+// [HH:MM:SS] [Thread/LEVEL]: msg, Forge 系额外带有 [logger]
+var LogLineFormat = regexp.MustCompile(`^\[([^\]]+)\] \[([^\]]*)/(\w+)\](?: \[[^\]]*\])?: (.*)$`)
+
+func ParseLogLine(raw string, locked bool) (line pluginabi.LogLine, ok bool) {
+	match := LogLineFormat.FindStringSubmatch(raw)
+	if len(match) != 5 {
+		return pluginabi.LogLine{Message: raw, Raw: raw, Locked: locked}, false
+	}
+	return pluginabi.LogLine{Time: match[1], Thread: match[2], Level: match[3], Message: match[4], Raw: raw, Locked: locked}, true
+}
+
+type structuredLogProcesser struct {
+	plugin  string
+	filter  pluginabi.LogFilter
+	channel chan pluginabi.LogLine
+	done    chan struct{}
+}
+
+func (p *structuredLogProcesser) match(line pluginabi.LogLine) bool {
+	if len(p.filter.Levels) > 0 && !slices.ContainsFunc(p.filter.Levels, func(level string) bool { return strings.EqualFold(level, line.Level) }) {
+		return false
+	}
+	return strings.HasPrefix(line.Message, p.filter.Prefix)
+}
+
+type structuredLogBus struct {
+	processers []*structuredLogProcesser
+	once       sync.Once
+	lock       sync.RWMutex
+}
+
+// 移除插件注册的结构化处理器
+func (b *structuredLogBus) unregister(plugin string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.processers = slices.DeleteFunc(b.processers, func(p *structuredLogProcesser) bool {
+		if p.plugin != plugin {
+			return false
+		}
+		close(p.done)
+		return true
+	})
+}
+
+// 所有结构化处理器共用一个消息通道, 每行日志只解析一次
+// 与原始日志处理器一致, 处理器的通道满时阻塞等待, 不丢弃日志
+func (mpm *MinecraftPluginManager) structuredLogWorker(channel chan pluginabi.LogLine) {
+	for line := range channel {
+		mpm.structuredLog.lock.RLock()
+		processers := slices.Clone(mpm.structuredLog.processers)
+		mpm.structuredLog.lock.RUnlock()
+		for _, p := range processers {
+			if !p.match(line) || mpm.PluginDisabled(p.plugin) {
+				continue
+			}
+			select {
+			case p.channel <- line:
+			case <-p.done:
+			}
+		}
+	}
+}
+
+func (mpm *MinecraftPluginManager) RegisterStructuredLogProcesser(context pluginabi.PluginName, filter pluginabi.LogFilter, process func(line pluginabi.LogLine)) {
+	mpm.structuredLog.once.Do(func() {
+		lines := make(chan pluginabi.LogLine, 16384)
+		mpm.RegisterLogProcesser(&pluginabi.PluginNameWrapper{PluginName: "StructuredLog", PluginDisplayName: "结构化日志"}, func(raw string, locked bool) {
+			line, _ := ParseLogLine(raw, locked)
+			lines <- line
+		})
+		go mpm.structuredLogWorker(lines)
+	})
+	pluginName := "anonymous"
+	p := &structuredLogProcesser{filter: filter, channel: make(chan pluginabi.LogLine, 16384), done: make(chan struct{})}
+	if context != nil {
+		pluginName = context.DisplayName()
+		p.plugin = context.Name()
+	}
+	mpm.kPrintln(color.YellowString("插件 "), color.BlueString(pluginName), color.YellowString(" 注册了一个结构化日志处理器: "), color.GreenString(GetFunctionName(process)))
+	go func() {
+		for {
+			select {
+			case line := <-p.channel:
+				process(line)
+			case <-p.done:
+				return
+			}
+		}
+	}()
+	mpm.structuredLog.lock.Lock()
+	mpm.structuredLog.processers = append(mpm.structuredLog.processers, p)
+	mpm.structuredLog.lock.Unlock()
+}
@@ -0,0 +1,201 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"github.com/fatih/color"
+)
+
+// 轴对齐长方体区域, Min/Max 均为包含边界
+type Area struct {
+	Name      string
+	Dimension string
+	Min       [3]float64
+	Max       [3]float64
+}
+
+func NewArea(name string, dimension string, a [3]float64, b [3]float64) Area {
+	area := Area{Name: name, Dimension: dimension}
+	for i := range 3 {
+		area.Min[i] = min(a[i], b[i])
+		area.Max[i] = max(a[i], b[i])
+	}
+	return area
+}
+
+func (a *Area) Contains(pos *MinecraftPosition) bool {
+	if pos == nil || pos.Dimension != a.Dimension {
+		return false
+	}
+	for i := range 3 {
+		if pos.Position[i] < a.Min[i] || pos.Position[i] > a.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type AreaHandler func(player string, area Area)
+
+type areaCore_Entry struct {
+	area    Area
+	onEnter AreaHandler
+	onExit  AreaHandler
+}
+
+type AreaCore struct {
+	BasePlugin
+	areas  map[string]*areaCore_Entry
+	inside map[string]map[string]bool // 玩家 -> 所在区域
+	lock   sync.RWMutex
+}
+
+func (ac *AreaCore) Init(pm pluginabi.PluginManager) error {
+	ac.BasePlugin.Init(pm, ac)
+	ac.areas = make(map[string]*areaCore_Entry)
+	ac.inside = make(map[string]map[string]bool)
+	if ac.playerInfo == nil {
+		return fmt.Errorf("no playerInfo instance")
+	}
+	ac.playerInfo.RegisterPositionHandler(ac.updatePosition)
+	ac.SubscribePlayerLeave(ac.playerLeave)
+	return nil
+}
+
+func (ac *AreaCore) key(context pluginabi.PluginName, name string) string {
+	return context.Name() + ":" + name
+}
+
+func (ac *AreaCore) RegisterArea(context pluginabi.PluginName, area Area, onEnter AreaHandler, onExit AreaHandler) error {
+	if area.Name == "" || strings.Contains(area.Name, ":") {
+		return fmt.Errorf("无效的区域名: %q", area.Name)
+	}
+	ac.lock.Lock()
+	defer ac.lock.Unlock()
+	key := ac.key(context, area.Name)
+	if _, ok := ac.areas[key]; ok {
+		return fmt.Errorf("区域 %s 已存在", area.Name)
+	}
+	ac.areas[key] = &areaCore_Entry{area: area, onEnter: onEnter, onExit: onExit}
+	ac.Println(color.YellowString("插件 "), color.BlueString(context.DisplayName()), color.YellowString(" 注册了区域: "), color.GreenString(area.Name))
+	return nil
+}
+
+// 移除区域时不触发离开回调
+func (ac *AreaCore) RemoveArea(context pluginabi.PluginName, name string) error {
+	ac.lock.Lock()
+	defer ac.lock.Unlock()
+	key := ac.key(context, name)
+	if _, ok := ac.areas[key]; !ok {
+		return fmt.Errorf("区域 %s 不存在", name)
+	}
+	delete(ac.areas, key)
+	for _, areas := range ac.inside {
+		delete(areas, key)
+	}
+	return nil
+}
+
+// 返回包含该位置的全部区域, 按名称排序
+func (ac *AreaCore) AreasAt(pos *MinecraftPosition) []Area {
+	ac.lock.RLock()
+	defer ac.lock.RUnlock()
+	areas := []Area{}
+	for _, entry := range ac.areas {
+		if entry.area.Contains(pos) {
+			areas = append(areas, entry.area)
+		}
+	}
+	slices.SortFunc(areas, func(a Area, b Area) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return areas
+}
+
+// 玩家当前所在的区域
+func (ac *AreaCore) PlayerAreas(player string) []Area {
+	ac.lock.RLock()
+	defer ac.lock.RUnlock()
+	areas := []Area{}
+	for key := range ac.inside[player] {
+		if entry, ok := ac.areas[key]; ok {
+			areas = append(areas, entry.area)
+		}
+	}
+	slices.SortFunc(areas, func(a Area, b Area) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return areas
+}
+
+func (ac *AreaCore) updatePosition(player string, _ *MinecraftPosition, to *MinecraftPosition) {
+	ac.lock.Lock()
+	defer ac.lock.Unlock()
+	current, ok := ac.inside[player]
+	if !ok {
+		current = make(map[string]bool)
+		ac.inside[player] = current
+	}
+	for key, entry := range ac.areas {
+		contains := entry.area.Contains(to)
+		switch {
+		case contains && !current[key]:
+			current[key] = true
+			if entry.onEnter != nil {
+				go entry.onEnter(player, entry.area)
+			}
+		case !contains && current[key]:
+			delete(current, key)
+			if entry.onExit != nil {
+				go entry.onExit(player, entry.area)
+			}
+		}
+	}
+}
+
+// 玩家退出时视为离开所有区域
+func (ac *AreaCore) playerLeave(player string) {
+	ac.lock.Lock()
+	defer ac.lock.Unlock()
+	for key := range ac.inside[player] {
+		if entry, ok := ac.areas[key]; ok && entry.onExit != nil {
+			go entry.onExit(player, entry.area)
+		}
+	}
+	delete(ac.inside, player)
+}
+
+func (ac *AreaCore) Name() string {
+	return "AreaCore"
+}
+
+func (ac *AreaCore) DisplayName() string {
+	return "区域内核"
+}
+
+func (ac *AreaCore) Start() {
+}
+
+func (ac *AreaCore) Pause() {
+	ac.lock.Lock()
+	ac.inside = make(map[string]map[string]bool)
+	ac.lock.Unlock()
+}
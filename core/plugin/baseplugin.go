@@ -17,10 +17,16 @@ package plugin
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
 	"github.com/fatih/color"
+	"github.com/samber/lo"
 )
 
 type BasePlugin struct {
@@ -31,6 +37,12 @@ type BasePlugin struct {
 	simpleCommand  *SimpleCommand
 	scoreboardCore *ScoreboardCore
 	tellrawManager *TellrawManager
+	bossbarCore    *BossbarCore
+	teamCore       *TeamCore
+	areaCore       *AreaCore
+	tasks          map[TaskHandle]chan struct{}
+	taskId         TaskHandle
+	taskLock       sync.Mutex
 }
 
 func (bp *BasePlugin) Println(a ...any) (int, error) {
@@ -41,31 +53,35 @@ func (bp *BasePlugin) Teleport(src string, dst any) error {
 	return bp.teleportCore.Teleport(src, dst)
 }
 
-func (bp *BasePlugin) initCorePlugin(pm pluginabi.PluginManager) {
-	pi := pm.GetPlugin("PlayerInfo")
-	if pi != nil {
-		bp.playerInfo = pi.(*PlayerInfo)
-	}
+// 按类型获取已注册的插件, T 为插件的指针类型, 插件名取自 T 的 Name(), 需能以 nil 接收者调用
+func Get[T pluginabi.Plugin](pm pluginabi.PluginManager) (T, error) {
+	var zero T
+	return GetByName[T](pm, zero.Name())
+}
 
-	sc := pm.GetPlugin("ScoreboardCore")
-	if sc != nil {
-		bp.scoreboardCore = sc.(*ScoreboardCore)
+func GetByName[T pluginabi.Plugin](pm pluginabi.PluginManager, name string) (T, error) {
+	var zero T
+	p := pm.GetPlugin(name)
+	if p == nil {
+		return zero, fmt.Errorf("插件 %s 未注册", name)
 	}
-
-	tm := pm.GetPlugin("TellrawManager")
-	if tm != nil {
-		bp.tellrawManager = tm.(*TellrawManager)
-	}
-
-	tc := pm.GetPlugin("TeleportCore")
-	if tc != nil {
-		bp.teleportCore = tc.(*TeleportCore)
+	t, ok := p.(T)
+	if !ok {
+		return zero, fmt.Errorf("插件 %s 的类型为 %T, 而非 %T", name, p, zero)
 	}
+	return t, nil
+}
 
-	sp := pm.GetPlugin("SimpleCommand")
-	if sp != nil {
-		bp.simpleCommand = sp.(*SimpleCommand)
-	}
+// 未注册的核心插件保持为 nil
+func (bp *BasePlugin) initCorePlugin(pm pluginabi.PluginManager) {
+	bp.playerInfo, _ = Get[*PlayerInfo](pm)
+	bp.scoreboardCore, _ = Get[*ScoreboardCore](pm)
+	bp.tellrawManager, _ = Get[*TellrawManager](pm)
+	bp.bossbarCore, _ = Get[*BossbarCore](pm)
+	bp.teamCore, _ = Get[*TeamCore](pm)
+	bp.teleportCore, _ = Get[*TeleportCore](pm)
+	bp.areaCore, _ = Get[*AreaCore](pm)
+	bp.simpleCommand, _ = Get[*SimpleCommand](pm)
 }
 
 func (bp *BasePlugin) Init(pm pluginabi.PluginManager, plugin pluginabi.Plugin) error {
@@ -75,18 +91,33 @@ func (bp *BasePlugin) Init(pm pluginabi.PluginManager, plugin pluginabi.Plugin)
 	return nil
 }
 
+func (bp *BasePlugin) scoreboardDisplayName(name string, displayName []tellraw.Message) string {
+	if len(displayName) == 0 {
+		return fmt.Sprintf(`"%s"`, name)
+	}
+	bName, _ := json.Marshal(displayName)
+	return string(bName)
+}
+
 func (bp *BasePlugin) EnsureScoreboard(name string, criterion string, displayName []tellraw.Message) {
 	if bp.scoreboardCore == nil {
 		return
 	}
-	dName := ""
-	if len(displayName) == 0 {
-		dName = fmt.Sprintf(`"%s"`, name)
-	} else {
-		bName, _ := json.Marshal(displayName)
-		dName = string(bName)
+	bp.scoreboardCore.ensureScoreboard(bp.p, name, criterion, bp.scoreboardDisplayName(name, displayName))
+}
+
+func (bp *BasePlugin) EnsureFixedScoreboard(name string, criterion string, displayName []tellraw.Message, scale int64) {
+	if bp.scoreboardCore == nil {
+		return
 	}
-	bp.scoreboardCore.ensureScoreboard(bp.p, name, criterion, dName)
+	bp.scoreboardCore.ensureScoreboard(bp.p, name, criterion, bp.scoreboardDisplayName(name, displayName), scale)
+}
+
+func (bp *BasePlugin) RemoveScoreboard(name string) error {
+	if bp.scoreboardCore == nil {
+		return fmt.Errorf("no scoreboardCore instance")
+	}
+	return bp.scoreboardCore.RemoveObjective(bp.p, name)
 }
 
 func (bp *BasePlugin) RegisterTrigger(trigger MinecraftTrigger) (name string) {
@@ -107,7 +138,36 @@ func (bp *BasePlugin) DisplayScoreboard(name string, slot string) {
 	if bp.scoreboardCore == nil {
 		return
 	}
-	bp.scoreboardCore.displayScoreboard(bp.p, name, slot)
+	bp.scoreboardCore.displayScoreboard(bp.p, name, slot, 0)
+}
+
+// 多个插件设置同一显示位时, 优先级高者显示, 其余排队等待
+func (bp *BasePlugin) DisplayScoreboardPriority(name string, slot string, priority int) {
+	if bp.scoreboardCore == nil {
+		return
+	}
+	bp.scoreboardCore.displayScoreboard(bp.p, name, slot, priority)
+}
+
+func (bp *BasePlugin) ClearDisplay(slot string) {
+	if bp.scoreboardCore == nil {
+		return
+	}
+	bp.scoreboardCore.ClearDisplay(bp.p, slot)
+}
+
+func (bp *BasePlugin) SetScoreboardRenderType(name string, rendertype string) error {
+	if bp.scoreboardCore == nil {
+		return fmt.Errorf("no scoreboardCore instance")
+	}
+	return bp.scoreboardCore.SetRenderType(bp.p, name, rendertype)
+}
+
+func (bp *BasePlugin) SetScoreboardNumberFormat(name string, format NumberFormat) error {
+	if bp.scoreboardCore == nil {
+		return fmt.Errorf("no scoreboardCore instance")
+	}
+	return bp.scoreboardCore.SetNumberFormat(bp.p, name, format)
 }
 
 func (bp *BasePlugin) ScoreAction(player string, name string, action string, count int64) {
@@ -117,6 +177,20 @@ func (bp *BasePlugin) ScoreAction(player string, name string, action string, cou
 	bp.scoreboardCore.scoreAction(bp.p, player, name, action, count)
 }
 
+func (bp *BasePlugin) ScoreActionFloat(player string, name string, action string, count float64) {
+	if bp.scoreboardCore == nil {
+		return
+	}
+	bp.scoreboardCore.scoreActionFloat(bp.p, player, name, action, count)
+}
+
+func (bp *BasePlugin) GetOneScoreFloat(player string, name string) (scores float64) {
+	if bp.scoreboardCore == nil {
+		return
+	}
+	return bp.scoreboardCore.getOneScoreFloat(bp.p, player, name)
+}
+
 func (bp *BasePlugin) GetAllScore() (scores map[string]map[string]int64) {
 	if bp.scoreboardCore == nil {
 		return
@@ -124,6 +198,27 @@ func (bp *BasePlugin) GetAllScore() (scores map[string]map[string]int64) {
 	return bp.scoreboardCore.getAllScore()
 }
 
+func (bp *BasePlugin) ScoreMessage(player string, name string) tellraw.Message {
+	if bp.scoreboardCore == nil {
+		return tellraw.Message{}
+	}
+	return tellraw.Message{Score: &tellraw.ScoreComponent{Name: player, Objective: bp.scoreboardCore.ObjectiveName(bp.p, name)}}
+}
+
+func (bp *BasePlugin) Leaderboard(name string, n int) []LeaderboardEntry {
+	if bp.scoreboardCore == nil {
+		return nil
+	}
+	return bp.scoreboardCore.Leaderboard(bp.p, name, n)
+}
+
+func (bp *BasePlugin) ExportScoreboardCSV(name string, w io.Writer) error {
+	if bp.scoreboardCore == nil {
+		return fmt.Errorf("no scoreboardCore instance")
+	}
+	return bp.scoreboardCore.ExportCSV(bp.scoreboardCore.ObjectiveName(bp.p, name), w)
+}
+
 func (bp *BasePlugin) GetOneScore(player string, name string) (scores int64) {
 	if bp.scoreboardCore == nil {
 		return
@@ -131,11 +226,29 @@ func (bp *BasePlugin) GetOneScore(player string, name string) (scores int64) {
 	return bp.scoreboardCore.getOneScore(bp.p, player, name)
 }
 
-func (bp *BasePlugin) RegisterCommand(command string, commandFunc func(string, ...string)) error {
+func (bp *BasePlugin) RegisterScoreWatcher(name string, cb ScoreWatcher) {
+	if bp.scoreboardCore == nil {
+		return
+	}
+	bp.scoreboardCore.RegisterScoreWatcher(bp.p, name, cb)
+}
+
+func (bp *BasePlugin) GetScoreboardCore() (*ScoreboardCore, error) {
+	return Get[*ScoreboardCore](bp.pm)
+}
+
+func (bp *BasePlugin) RegisterCommand(command string, commandFunc func(string, ...string), opts ...CommandOption) error {
 	if bp.simpleCommand == nil {
 		return fmt.Errorf("no simplecommand instance")
 	}
-	return bp.simpleCommand.RegisterCommand(bp.p, command, commandFunc)
+	return bp.simpleCommand.RegisterCommand(bp.p, command, commandFunc, opts...)
+}
+
+func (bp *BasePlugin) RegisterCommandCompleter(command string, completer func(args []string) []string) error {
+	if bp.simpleCommand == nil {
+		return fmt.Errorf("no simplecommand instance")
+	}
+	return bp.simpleCommand.RegisterCommandCompleter(bp.p, command, completer)
 }
 
 func (bp *BasePlugin) GetPlayerInfo_Position(player string) (*MinecraftPlayerInfo, error) {
@@ -144,6 +257,48 @@ func (bp *BasePlugin) GetPlayerInfo_Position(player string) (*MinecraftPlayerInf
 	}
 	return bp.playerInfo.GetPlayerInfo_Position(player)
 }
+
+func (bp *BasePlugin) GetPlayerInfoByUUID(uuid string) (*MinecraftPlayerInfo, error) {
+	if bp.playerInfo == nil {
+		return nil, fmt.Errorf("no playerInfo instance")
+	}
+	return bp.playerInfo.GetPlayerInfoByUUID(uuid)
+}
+
+func (bp *BasePlugin) SetDisplayName(player string, nick string) error {
+	if bp.playerInfo == nil {
+		return fmt.Errorf("no playerInfo instance")
+	}
+	return bp.playerInfo.SetDisplayName(player, nick)
+}
+
+func (bp *BasePlugin) GetDisplayName(player string) string {
+	if bp.playerInfo == nil {
+		return player
+	}
+	return bp.playerInfo.GetDisplayName(player)
+}
+
+func (bp *BasePlugin) ResolveName(input string) (string, bool) {
+	if bp.playerInfo == nil {
+		return input, false
+	}
+	return bp.playerInfo.ResolveName(input)
+}
+
+func (bp *BasePlugin) GetInventory(player string) ([]ItemStack, error) {
+	if bp.playerInfo == nil {
+		return nil, fmt.Errorf("no playerInfo instance")
+	}
+	return bp.playerInfo.GetInventory(player)
+}
+
+func (bp *BasePlugin) GetPlayerInfo_Full(player string) (*MinecraftPlayerInfo, error) {
+	if bp.playerInfo == nil {
+		return nil, fmt.Errorf("no playerInfo instance")
+	}
+	return bp.playerInfo.GetPlayerInfo_Full(player)
+}
 func (bp *BasePlugin) GetPlayerInfo(player string) (*MinecraftPlayerInfo, error) {
 	if bp.playerInfo == nil {
 		return nil, fmt.Errorf("no playerInfo instance")
@@ -151,6 +306,41 @@ func (bp *BasePlugin) GetPlayerInfo(player string) (*MinecraftPlayerInfo, error)
 	return bp.playerInfo.GetPlayerInfo(player)
 }
 
+func (bp *BasePlugin) LookupPlayerInfo(player string) (*MinecraftPlayerInfo, bool) {
+	if bp.playerInfo == nil {
+		return nil, false
+	}
+	return bp.playerInfo.LookupPlayerInfo(player)
+}
+
+func (bp *BasePlugin) GetOfflinePlayerInfo(uuid string) (*MinecraftPlayerInfo, error) {
+	if bp.playerInfo == nil {
+		return nil, fmt.Errorf("no playerInfo instance")
+	}
+	return bp.playerInfo.GetOfflinePlayerInfo(uuid)
+}
+
+func (bp *BasePlugin) GetPlaytime(player string) time.Duration {
+	if bp.playerInfo == nil {
+		return 0
+	}
+	return bp.playerInfo.GetPlaytime(player)
+}
+
+func (bp *BasePlugin) GetLastSeen(player string) (time.Time, bool) {
+	if bp.playerInfo == nil {
+		return time.Time{}, false
+	}
+	return bp.playerInfo.GetLastSeen(player)
+}
+
+func (bp *BasePlugin) GetPositionHistory(player string) []TimedPosition {
+	if bp.playerInfo == nil {
+		return nil
+	}
+	return bp.playerInfo.GetPositionHistory(player)
+}
+
 func (bp *BasePlugin) GetPlayerList() []string {
 	if bp.playerInfo == nil {
 		return nil
@@ -158,26 +348,372 @@ func (bp *BasePlugin) GetPlayerList() []string {
 	return bp.playerInfo.GetPlayerList()
 }
 
+func (bp *BasePlugin) RegisterJoinHandler(cb func(player string)) error {
+	if bp.playerInfo == nil {
+		return fmt.Errorf("no playerInfo instance")
+	}
+	bp.playerInfo.RegisterJoinHandler(cb)
+	return nil
+}
+
+func (bp *BasePlugin) RegisterLeaveHandler(cb func(player string)) error {
+	if bp.playerInfo == nil {
+		return fmt.Errorf("no playerInfo instance")
+	}
+	bp.playerInfo.RegisterLeaveHandler(cb)
+	return nil
+}
+
+func (bp *BasePlugin) RegisterDimensionChangeHandler(cb func(player string, from string, to string)) error {
+	if bp.playerInfo == nil {
+		return fmt.Errorf("no playerInfo instance")
+	}
+	bp.playerInfo.RegisterDimensionChangeHandler(cb)
+	return nil
+}
+
+func (bp *BasePlugin) GetDistanceTraveled(player string) float64 {
+	if bp.playerInfo == nil {
+		return 0
+	}
+	return bp.playerInfo.GetDistanceTraveled(player)
+}
+
+// 注册本插件 Extra 数据从 fromVersion 到 fromVersion+1 的迁移
+func (bp *BasePlugin) RegisterMigration(fromVersion int, fn func(old json.RawMessage) (json.RawMessage, error)) error {
+	if bp.playerInfo == nil {
+		return fmt.Errorf("no playerInfo instance")
+	}
+	return bp.playerInfo.RegisterMigration(bp.p, fromVersion, fn)
+}
+
+// 服务端不提供延迟时 ok 为 false
+func (bp *BasePlugin) GetPing(player string) (int, bool) {
+	if bp.playerInfo == nil {
+		return 0, false
+	}
+	return bp.playerInfo.GetPing(player)
+}
+
+func (bp *BasePlugin) IsAfk(player string) bool {
+	if bp.playerInfo == nil {
+		return false
+	}
+	return bp.playerInfo.IsAfk(player)
+}
+
+func (bp *BasePlugin) RegisterAfkHandler(cb func(player string, afk bool)) error {
+	if bp.playerInfo == nil {
+		return fmt.Errorf("no playerInfo instance")
+	}
+	bp.playerInfo.RegisterAfkHandler(cb)
+	return nil
+}
+
+func (bp *BasePlugin) RegisterPositionHandler(cb func(player string, from *MinecraftPosition, to *MinecraftPosition)) error {
+	if bp.playerInfo == nil {
+		return fmt.Errorf("no playerInfo instance")
+	}
+	bp.playerInfo.RegisterPositionHandler(cb)
+	return nil
+}
+
+func (bp *BasePlugin) RegisterArea(area Area, onEnter AreaHandler, onExit AreaHandler) error {
+	if bp.areaCore == nil {
+		return fmt.Errorf("no areaCore instance")
+	}
+	return bp.areaCore.RegisterArea(bp.p, area, onEnter, onExit)
+}
+
+func (bp *BasePlugin) RemoveArea(name string) error {
+	if bp.areaCore == nil {
+		return fmt.Errorf("no areaCore instance")
+	}
+	return bp.areaCore.RemoveArea(bp.p, name)
+}
+
+func (bp *BasePlugin) GetAreasAt(pos *MinecraftPosition) []Area {
+	if bp.areaCore == nil {
+		return nil
+	}
+	return bp.areaCore.AreasAt(pos)
+}
+
+func (bp *BasePlugin) GetPlayerAreas(player string) []Area {
+	if bp.areaCore == nil {
+		return nil
+	}
+	return bp.areaCore.PlayerAreas(player)
+}
+
+func (bp *BasePlugin) RegisterDeathHandler(cb func(player string, cause string, killer string)) error {
+	if bp.playerInfo == nil {
+		return fmt.Errorf("no playerInfo instance")
+	}
+	bp.playerInfo.RegisterDeathHandler(cb)
+	return nil
+}
+
+func (bp *BasePlugin) RegisterStructuredLogProcesser(filter pluginabi.LogFilter, process func(line pluginabi.LogLine)) {
+	bp.pm.RegisterStructuredLogProcesser(bp.p, filter, process)
+}
+
+// 插件数据文件路径, 位于管理器配置的数据目录下
+func (bp *BasePlugin) DataPath(name string) string {
+	return bp.pm.DataPath(name)
+}
+
 func (bp *BasePlugin) RunCommand(command string) string {
 	return bp.pm.RunCommand(command)
 }
 
-func (bp *BasePlugin) Tellraw(Target string, msg []tellraw.Message) {
-	bp.tellrawManager.Tellraw(bp.p, Target, msg)
+func (bp *BasePlugin) RunCommandAsync(command string) <-chan string {
+	return bp.pm.RunCommandAsync(command)
+}
+
+func (bp *BasePlugin) RunCommandTimeout(command string, d time.Duration) (string, error) {
+	return bp.pm.RunCommandTimeout(command, d)
+}
+
+// 校验命令目标, 返回可直接拼入命令的选择器或在线玩家的账户名
+// 目标可以是玩家昵称, 不是选择器时只接受合法的玩家名, 避免拼接出其他命令参数
+func (bp *BasePlugin) resolveTarget(Target string) (string, error) {
+	if strings.HasPrefix(Target, "@") {
+		if !TellrawSelector.MatchString(Target) {
+			return "", fmt.Errorf("无效的目标选择器: %s", Target)
+		}
+		return Target, nil
+	}
+	if bp.playerInfo != nil {
+		if account, ok := bp.playerInfo.ResolveName(Target); ok {
+			Target = account
+		}
+	}
+	if !PlayerNamePattern.MatchString(Target) {
+		return "", fmt.Errorf("无效的玩家名: %q", Target)
+	}
+	if bp.playerInfo != nil && !slices.Contains(bp.playerInfo.GetPlayerList(), Target) {
+		return "", fmt.Errorf("玩家 %s 不在线", Target)
+	}
+	return Target, nil
+}
+
+// 目标为选择器或在线玩家, 目标无法接收消息时返回错误
+func (bp *BasePlugin) Tellraw(Target string, msg []tellraw.Message) error {
+	if bp.tellrawManager == nil {
+		return fmt.Errorf("no tellrawManager instance")
+	}
+	Target, err := bp.resolveTarget(Target)
+	if err != nil {
+		return err
+	}
+	return bp.tellrawManager.Tellraw(bp.p, Target, msg)
+}
+
+// 向多名玩家发送同一消息, 只发送给列出的玩家, 选择器与不在线的玩家会被忽略
+func (bp *BasePlugin) TellrawMulti(players []string, msg []tellraw.Message) error {
+	if bp.tellrawManager == nil {
+		return fmt.Errorf("no tellrawManager instance")
+	}
+	targets := lo.Uniq(lo.FilterMap(players, func(player string, _ int) (string, bool) {
+		if strings.HasPrefix(player, "@") {
+			return "", false
+		}
+		target, err := bp.resolveTarget(player)
+		return target, err == nil
+	}))
+	return bp.tellrawManager.TellrawMulti(bp.p, targets, msg)
+}
+
+// 不校验目标, 用于复杂选择器等场景
+func (bp *BasePlugin) TellrawRaw(Target string, msg []tellraw.Message) error {
+	if bp.tellrawManager == nil {
+		return fmt.Errorf("no tellrawManager instance")
+	}
+	return bp.tellrawManager.Tellraw(bp.p, Target, msg)
+}
+
+func (bp *BasePlugin) Title(Target string, title []tellraw.Message, subtitle []tellraw.Message, fadeIn int, stay int, fadeOut int) error {
+	if bp.tellrawManager == nil {
+		return fmt.Errorf("no tellrawManager instance")
+	}
+	Target, err := bp.resolveTarget(Target)
+	if err != nil {
+		return err
+	}
+	return bp.tellrawManager.Title(Target, title, subtitle, fadeIn, stay, fadeOut)
 }
 
+func (bp *BasePlugin) ActionBar(Target string, msg []tellraw.Message) error {
+	if bp.tellrawManager == nil {
+		return fmt.Errorf("no tellrawManager instance")
+	}
+	Target, err := bp.resolveTarget(Target)
+	if err != nil {
+		return err
+	}
+	return bp.tellrawManager.ActionBar(Target, msg)
+}
+
+func (bp *BasePlugin) CreateBossbar(id string, name []tellraw.Message) error {
+	if bp.bossbarCore == nil {
+		return fmt.Errorf("no bossbarCore instance")
+	}
+	return bp.bossbarCore.CreateBossbar(bp.p, id, name)
+}
+
+func (bp *BasePlugin) SetBossbarName(id string, name []tellraw.Message) error {
+	if bp.bossbarCore == nil {
+		return fmt.Errorf("no bossbarCore instance")
+	}
+	return bp.bossbarCore.SetBossbarName(bp.p, id, name)
+}
+
+func (bp *BasePlugin) SetBossbarProgress(id string, value int, max int) error {
+	if bp.bossbarCore == nil {
+		return fmt.Errorf("no bossbarCore instance")
+	}
+	return bp.bossbarCore.SetBossbarProgress(bp.p, id, value, max)
+}
+
+func (bp *BasePlugin) SetBossbarColor(id string, bossbarColor string) error {
+	if bp.bossbarCore == nil {
+		return fmt.Errorf("no bossbarCore instance")
+	}
+	return bp.bossbarCore.SetBossbarColor(bp.p, id, bossbarColor)
+}
+
+func (bp *BasePlugin) SetBossbarStyle(id string, style string) error {
+	if bp.bossbarCore == nil {
+		return fmt.Errorf("no bossbarCore instance")
+	}
+	return bp.bossbarCore.SetBossbarStyle(bp.p, id, style)
+}
+
+func (bp *BasePlugin) SetBossbarPlayers(id string, target string) error {
+	if bp.bossbarCore == nil {
+		return fmt.Errorf("no bossbarCore instance")
+	}
+	return bp.bossbarCore.SetBossbarPlayers(bp.p, id, target)
+}
+
+func (bp *BasePlugin) SetBossbarVisible(id string, visible bool) error {
+	if bp.bossbarCore == nil {
+		return fmt.Errorf("no bossbarCore instance")
+	}
+	return bp.bossbarCore.SetBossbarVisible(bp.p, id, visible)
+}
+
+func (bp *BasePlugin) RemoveBossbar(id string) error {
+	if bp.bossbarCore == nil {
+		return fmt.Errorf("no bossbarCore instance")
+	}
+	return bp.bossbarCore.RemoveBossbar(bp.p, id)
+}
+
+func (bp *BasePlugin) CreateTeam(id string, displayName []tellraw.Message) error {
+	if bp.teamCore == nil {
+		return fmt.Errorf("no teamCore instance")
+	}
+	return bp.teamCore.CreateTeam(bp.p, id, displayName)
+}
+
+func (bp *BasePlugin) SetTeamDisplayName(id string, displayName []tellraw.Message) error {
+	if bp.teamCore == nil {
+		return fmt.Errorf("no teamCore instance")
+	}
+	return bp.teamCore.SetTeamDisplayName(bp.p, id, displayName)
+}
+
+func (bp *BasePlugin) SetTeamColor(id string, teamColor string) error {
+	if bp.teamCore == nil {
+		return fmt.Errorf("no teamCore instance")
+	}
+	return bp.teamCore.SetTeamColor(bp.p, id, teamColor)
+}
+
+func (bp *BasePlugin) SetTeamPrefix(id string, prefix []tellraw.Message) error {
+	if bp.teamCore == nil {
+		return fmt.Errorf("no teamCore instance")
+	}
+	return bp.teamCore.SetTeamPrefix(bp.p, id, prefix)
+}
+
+func (bp *BasePlugin) SetTeamSuffix(id string, suffix []tellraw.Message) error {
+	if bp.teamCore == nil {
+		return fmt.Errorf("no teamCore instance")
+	}
+	return bp.teamCore.SetTeamSuffix(bp.p, id, suffix)
+}
+
+func (bp *BasePlugin) SetTeamCollisionRule(id string, rule string) error {
+	if bp.teamCore == nil {
+		return fmt.Errorf("no teamCore instance")
+	}
+	return bp.teamCore.SetTeamCollisionRule(bp.p, id, rule)
+}
+
+func (bp *BasePlugin) SetTeamNametagVisibility(id string, visibility string) error {
+	if bp.teamCore == nil {
+		return fmt.Errorf("no teamCore instance")
+	}
+	return bp.teamCore.SetTeamNametagVisibility(bp.p, id, visibility)
+}
+
+func (bp *BasePlugin) JoinTeam(id string, members ...string) error {
+	if bp.teamCore == nil {
+		return fmt.Errorf("no teamCore instance")
+	}
+	return bp.teamCore.JoinTeam(bp.p, id, members...)
+}
+
+func (bp *BasePlugin) LeaveTeam(members ...string) error {
+	if bp.teamCore == nil {
+		return fmt.Errorf("no teamCore instance")
+	}
+	return bp.teamCore.LeaveTeam(bp.p, members...)
+}
+
+func (bp *BasePlugin) EmptyTeam(id string) error {
+	if bp.teamCore == nil {
+		return fmt.Errorf("no teamCore instance")
+	}
+	return bp.teamCore.EmptyTeam(bp.p, id)
+}
+
+func (bp *BasePlugin) RemoveTeam(id string) error {
+	if bp.teamCore == nil {
+		return fmt.Errorf("no teamCore instance")
+	}
+	return bp.teamCore.RemoveTeam(bp.p, id)
+}
+
+func (bp *BasePlugin) TeamName(id string) string {
+	if bp.teamCore == nil {
+		return id
+	}
+	return bp.teamCore.TeamName(bp.p, id)
+}
+
+// 错误详情输出到控制台, 目标为玩家时同时告知该玩家, 不向选择器广播
 func (bp *BasePlugin) TellrawError(Target string, err error) {
 	if err == nil {
 		return
 	}
-	bp.Tellraw("@a", []tellraw.Message{{Text: "内部错误", Color: tellraw.Red}, {Text: err.Error(), Color: tellraw.Yellow}})
+	bp.Println(color.RedString("内部错误: "), color.MagentaString(err.Error()))
+	if strings.HasPrefix(Target, "@") {
+		return
+	}
+	bp.Tellraw(Target, []tellraw.Message{{Text: "内部错误", Color: tellraw.Red}, {Text: err.Error(), Color: tellraw.Yellow}})
 }
 
 func (bp *BasePlugin) GetWorldName(namespace_id string) string {
-	if name, ok := worldName[namespace_id]; ok {
-		return name
-	}
-	return namespace_id
+	return worldNames.get(namespace_id)
+}
+
+// 为自定义维度注册显示名称, 配置文件中的名称优先
+func (bp *BasePlugin) RegisterWorldName(namespace_id string, name string) {
+	worldNames.register(namespace_id, name)
 }
 
 func (bp *BasePlugin) Name() string {
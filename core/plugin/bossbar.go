@@ -0,0 +1,189 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
+	"github.com/cespare/xxhash/v2"
+	"github.com/fatih/color"
+	"golang.org/x/exp/maps"
+)
+
+var BossbarColor = []string{"blue", "green", "pink", "purple", "red", "white", "yellow"}
+var BossbarStyle = []string{"progress", "notched_6", "notched_10", "notched_12", "notched_20"}
+var BossbarId = regexp.MustCompile(`^[a-z0-9_.-]+$`)
+var BossbarNotExist = regexp.MustCompile(`No bossbar exists`)
+
+type BossbarCore struct {
+	BasePlugin
+	bossbar map[string]map[string]struct{}
+	lock    sync.Mutex
+}
+
+func (bc *BossbarCore) Init(pm pluginabi.PluginManager) (err error) {
+	err = bc.BasePlugin.Init(pm, bc)
+	if err != nil {
+		return err
+	}
+	bc.bossbar = make(map[string]map[string]struct{})
+	return nil
+}
+
+// bossbar id 需为小写的资源路径, 使用十六进制哈希作为命名空间
+func (bc *BossbarCore) bossbarId(pluginName string, id string) string {
+	return fmt.Sprintf("plugin:%08x_%s", uint32(xxhash.Sum64String(pluginName)), id)
+}
+
+func (bc *BossbarCore) getBossbar(context pluginabi.PluginName, id string) (string, error) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	if _, ok := bc.bossbar[context.Name()][id]; !ok {
+		return "", fmt.Errorf("bossbar not exist")
+	}
+	return bc.bossbarId(context.Name(), id), nil
+}
+
+func (bc *BossbarCore) set(context pluginabi.PluginName, id string, property string, value string) error {
+	fullId, err := bc.getBossbar(context, id)
+	if err != nil {
+		return err
+	}
+	res := bc.RunCommand(fmt.Sprintf("bossbar set %s %s %s", fullId, property, value))
+	if BossbarNotExist.MatchString(res) {
+		return fmt.Errorf("bossbar not exist")
+	}
+	return nil
+}
+
+func (bc *BossbarCore) CreateBossbar(context pluginabi.PluginName, id string, name []tellraw.Message) error {
+	if !BossbarId.MatchString(id) {
+		return fmt.Errorf("invalid bossbar id: %s", id)
+	}
+	nameMsg, err := bc.tellrawManager.marshal(name)
+	if err != nil {
+		return err
+	}
+	fullId := bc.bossbarId(context.Name(), id)
+	// 已存在时沿用现有 bossbar, 仅更新名称
+	bc.RunCommand(fmt.Sprintf("bossbar add %s %s", fullId, nameMsg))
+	bc.RunCommand(fmt.Sprintf("bossbar set %s name %s", fullId, nameMsg))
+	bc.lock.Lock()
+	if _, ok := bc.bossbar[context.Name()]; !ok {
+		bc.bossbar[context.Name()] = make(map[string]struct{})
+	}
+	bc.bossbar[context.Name()][id] = struct{}{}
+	bc.lock.Unlock()
+	bc.Println(
+		color.YellowString("插件 "),
+		color.BlueString(context.DisplayName()),
+		color.YellowString(" 创建了 Bossbar "),
+		color.GreenString(id),
+	)
+	return nil
+}
+
+func (bc *BossbarCore) SetBossbarName(context pluginabi.PluginName, id string, name []tellraw.Message) error {
+	nameMsg, err := bc.tellrawManager.marshal(name)
+	if err != nil {
+		return err
+	}
+	return bc.set(context, id, "name", nameMsg)
+}
+
+func (bc *BossbarCore) SetBossbarProgress(context pluginabi.PluginName, id string, value int, max int) error {
+	if max <= 0 || value < 0 {
+		return fmt.Errorf("invalid bossbar progress: %d/%d", value, max)
+	}
+	err := bc.set(context, id, "max", fmt.Sprintf("%d", max))
+	if err != nil {
+		return err
+	}
+	return bc.set(context, id, "value", fmt.Sprintf("%d", min(value, max)))
+}
+
+func (bc *BossbarCore) SetBossbarColor(context pluginabi.PluginName, id string, bossbarColor string) error {
+	if !slices.Contains(BossbarColor, bossbarColor) {
+		return fmt.Errorf("invalid bossbar color: %s", bossbarColor)
+	}
+	return bc.set(context, id, "color", bossbarColor)
+}
+
+func (bc *BossbarCore) SetBossbarStyle(context pluginabi.PluginName, id string, style string) error {
+	if !slices.Contains(BossbarStyle, style) {
+		return fmt.Errorf("invalid bossbar style: %s", style)
+	}
+	return bc.set(context, id, "style", style)
+}
+
+// target 为空时清空可见玩家
+func (bc *BossbarCore) SetBossbarPlayers(context pluginabi.PluginName, id string, target string) error {
+	return bc.set(context, id, "players", target)
+}
+
+func (bc *BossbarCore) SetBossbarVisible(context pluginabi.PluginName, id string, visible bool) error {
+	return bc.set(context, id, "visible", fmt.Sprintf("%t", visible))
+}
+
+func (bc *BossbarCore) RemoveBossbar(context pluginabi.PluginName, id string) error {
+	fullId, err := bc.getBossbar(context, id)
+	if err != nil {
+		return err
+	}
+	bc.RunCommand(fmt.Sprintf("bossbar remove %s", fullId))
+	bc.lock.Lock()
+	delete(bc.bossbar[context.Name()], id)
+	bc.lock.Unlock()
+	return nil
+}
+
+func (bc *BossbarCore) RemoveAllBossbars(context pluginabi.PluginName) {
+	bc.lock.Lock()
+	ids := maps.Keys(bc.bossbar[context.Name()])
+	delete(bc.bossbar, context.Name())
+	bc.lock.Unlock()
+	for _, id := range ids {
+		bc.RunCommand(fmt.Sprintf("bossbar remove %s", bc.bossbarId(context.Name(), id)))
+	}
+}
+
+func (bc *BossbarCore) Name() string {
+	return "BossbarCore"
+}
+
+func (bc *BossbarCore) DisplayName() string {
+	return "Bossbar核心"
+}
+
+func (bc *BossbarCore) Start() {
+}
+
+// bossbar 会保存在存档中, 暂停时全部移除以免残留
+func (bc *BossbarCore) Pause() {
+	bc.lock.Lock()
+	bossbar := bc.bossbar
+	bc.bossbar = make(map[string]map[string]struct{})
+	bc.lock.Unlock()
+	for plugin, ids := range bossbar {
+		for id := range ids {
+			bc.RunCommand(fmt.Sprintf("bossbar remove %s", bc.bossbarId(plugin, id)))
+		}
+	}
+}
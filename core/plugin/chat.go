@@ -0,0 +1,85 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+var ChatMessage = regexp.MustCompile(`^.*?\]:(?: \[[^\]]+\])? <([^<>\s]+)> (.*)$`)
+
+// 格式无效时退回原版格式
+func (pi *PlayerInfo) compileChatFormat(format string) *regexp.Regexp {
+	if format == "" {
+		return ChatMessage
+	}
+	re, err := regexp.Compile(format)
+	if err == nil && re.NumSubexp() < 2 {
+		err = fmt.Errorf("需要至少两个捕获组")
+	}
+	if err != nil {
+		pi.Println(color.RedString("无效的聊天格式: "), color.MagentaString(err.Error()), color.RedString(", 使用原版格式"))
+		return ChatMessage
+	}
+	pi.Println(color.YellowString("使用自定义聊天格式: "), color.GreenString(format))
+	return re
+}
+
+func (pi *PlayerInfo) chatEvent(log string, _ bool) {
+	match := pi.chatFormat.FindStringSubmatch(log)
+	if len(match) < 3 {
+		return
+	}
+	player, message := match[1], match[2]
+	// 昵称插件会在聊天中显示昵称, 统一解析为账户名
+	if account, ok := pi.ResolveName(player); ok {
+		player = account
+	}
+	pi.markActive(player)
+	pi.handlerLock.RLock()
+	for _, handler := range pi.chatHandler {
+		go handler(player, message)
+	}
+	pi.handlerLock.RUnlock()
+	pi.Publish(EventPlayerChat, PlayerChatEvent{Player: player, DisplayName: pi.GetDisplayName(player), Message: message})
+}
+
+func (pi *PlayerInfo) RegisterChatHandler(cb func(player string, message string)) {
+	pi.handlerLock.Lock()
+	defer pi.handlerLock.Unlock()
+	pi.chatHandler = append(pi.chatHandler, cb)
+}
+
+// 以服务器身份发送聊天消息
+func (bp *BasePlugin) Say(msg string) error {
+	msg = strings.TrimSpace(strings.ReplaceAll(msg, "\n", " "))
+	if msg == "" {
+		return fmt.Errorf("empty message")
+	}
+	bp.RunCommand("say " + msg)
+	return nil
+}
+
+func (bp *BasePlugin) RegisterChatHandler(cb func(player string, message string)) error {
+	if bp.playerInfo == nil {
+		return fmt.Errorf("no playerInfo instance")
+	}
+	bp.playerInfo.RegisterChatHandler(cb)
+	return nil
+}
@@ -0,0 +1,254 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
+)
+
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	// 吃掉剩余全部参数, 只能作为最后一个参数
+	Rest
+)
+
+func (t Type) String() string {
+	switch t {
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Bool:
+		return "bool"
+	case Rest:
+		return "text..."
+	}
+	return "string"
+}
+
+type ArgSpec struct {
+	Name       string
+	Type       Type
+	Required   bool
+	Default    any
+	hasDefault bool
+}
+
+type Option func(arg *ArgSpec)
+
+func Required(arg *ArgSpec) {
+	arg.Required = true
+}
+
+func Default(v any) Option {
+	return func(arg *ArgSpec) {
+		arg.Default = v
+		arg.hasDefault = true
+	}
+}
+
+func Arg(name string, t Type, opts ...Option) *ArgSpec {
+	arg := &ArgSpec{Name: name, Type: t}
+	for _, opt := range opts {
+		opt(arg)
+	}
+	return arg
+}
+
+func (arg *ArgSpec) usage() string {
+	name := arg.Name
+	if arg.Type != String {
+		name += ":" + arg.Type.String()
+	}
+	if arg.Required {
+		return "<" + name + ">"
+	}
+	if arg.hasDefault {
+		name += fmt.Sprintf("=%v", arg.Default)
+	}
+	return "[" + name + "]"
+}
+
+func (arg *ArgSpec) parse(raw string) (any, error) {
+	switch arg.Type {
+	case Int:
+		return strconv.Atoi(raw)
+	case Float:
+		return strconv.ParseFloat(raw, 64)
+	case Bool:
+		return strconv.ParseBool(raw)
+	}
+	return raw, nil
+}
+
+type Spec struct {
+	Command string
+	Args    []*ArgSpec
+}
+
+func New(command string, args ...*ArgSpec) *Spec {
+	return &Spec{Command: command, Args: args}
+}
+
+func (s *Spec) Usage() string {
+	parts := []string{"!!" + s.Command}
+	for _, arg := range s.Args {
+		parts = append(parts, arg.usage())
+	}
+	return strings.Join(parts, " ")
+}
+
+type UsageError struct {
+	Spec   *Spec
+	Arg    string
+	Reason string
+}
+
+func (e *UsageError) Error() string {
+	if e.Arg == "" {
+		return fmt.Sprintf("%s (用法: %s)", e.Reason, e.Spec.Usage())
+	}
+	return fmt.Sprintf("参数 %s %s (用法: %s)", e.Arg, e.Reason, e.Spec.Usage())
+}
+
+// 生成可直接发送给玩家的用法提示, 点击填入命令
+func (e *UsageError) Tellraw() []tellraw.Message {
+	msg := []tellraw.Message{}
+	if e.Arg != "" {
+		msg = append(msg, tellraw.Message{Text: "参数 ", Color: tellraw.Red}, tellraw.Message{Text: e.Arg, Color: tellraw.Yellow}, tellraw.Message{Text: " ", Color: tellraw.Red})
+	}
+	msg = append(msg,
+		tellraw.Message{Text: e.Reason, Color: tellraw.Red},
+		tellraw.Message{Text: "\n用法: ", Color: tellraw.Aqua},
+		tellraw.Message{
+			Text:       e.Spec.Usage(),
+			Color:      tellraw.Green,
+			ClickEvent: &tellraw.ClickEvent{Action: tellraw.SuggestCommand, Value: "!!" + e.Spec.Command + " "},
+		},
+	)
+	return msg
+}
+
+type Values map[string]any
+
+func (v Values) Has(name string) bool {
+	_, ok := v[name]
+	return ok
+}
+
+func (v Values) String(name string) string {
+	s, _ := v[name].(string)
+	return s
+}
+
+func (v Values) Int(name string) int {
+	i, _ := v[name].(int)
+	return i
+}
+
+func (v Values) Float(name string) float64 {
+	f, _ := v[name].(float64)
+	return f
+}
+
+func (v Values) Bool(name string) bool {
+	b, _ := v[name].(bool)
+	return b
+}
+
+// 按 Spec 解析命令参数, args 为 RegisterCommand 回调收到的原始参数
+func (s *Spec) Parse(args []string) (Values, error) {
+	tokens, err := Split(strings.Join(args, " "))
+	if err != nil {
+		return nil, &UsageError{Spec: s, Reason: err.Error()}
+	}
+	values := Values{}
+	for i, arg := range s.Args {
+		if arg.Type == Rest {
+			if i < len(tokens) {
+				values[arg.Name] = strings.Join(tokens[i:], " ")
+				tokens = tokens[:i]
+			}
+		} else if i < len(tokens) {
+			v, err := arg.parse(tokens[i])
+			if err != nil {
+				return nil, &UsageError{Spec: s, Arg: arg.Name, Reason: fmt.Sprintf("应为 %s, 实际为 %q", arg.Type, tokens[i])}
+			}
+			values[arg.Name] = v
+		}
+		if !values.Has(arg.Name) {
+			if arg.Required {
+				return nil, &UsageError{Spec: s, Arg: arg.Name, Reason: "缺失"}
+			}
+			if arg.hasDefault {
+				values[arg.Name] = arg.Default
+			}
+		}
+	}
+	if len(tokens) > len(s.Args) {
+		return nil, &UsageError{Spec: s, Reason: fmt.Sprintf("多余的参数: %s", strings.Join(tokens[len(s.Args):], " "))}
+	}
+	return values, nil
+}
+
+// 按空白切分参数, 支持单/双引号包裹含空格的参数以及反斜杠转义
+func Split(raw string) ([]string, error) {
+	tokens := []string{}
+	var token strings.Builder
+	var quote rune
+	inToken, escaped := false, false
+	for _, c := range raw {
+		switch {
+		case escaped:
+			token.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped, inToken = true, true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				token.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote, inToken = c, true
+		case c == ' ' || c == '\t':
+			if inToken {
+				tokens = append(tokens, token.String())
+				token.Reset()
+				inToken = false
+			}
+		default:
+			token.WriteRune(c)
+			inToken = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("引号未闭合")
+	}
+	if inToken {
+		tokens = append(tokens, token.String())
+	}
+	return tokens, nil
+}
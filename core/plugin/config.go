@@ -0,0 +1,88 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+)
+
+func (bp *BasePlugin) configPath() string {
+	return filepath.Join("config", bp.p.Name()+".json")
+}
+
+// 读取 config/<PluginName>.json 到 v, 文件不存在时以 v 当前的值作为默认配置写入
+func (bp *BasePlugin) LoadConfig(v any) error {
+	path := bp.configPath()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		bp.Println(color.YellowString("配置文件不存在, 生成默认配置: "), color.BlueString(path))
+		return bp.SaveConfig(v)
+	}
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(v)
+	if err != nil {
+		err = configError(path, data, err)
+		bp.Println(color.RedString("配置文件解析失败: "), color.MagentaString(err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (bp *BasePlugin) SaveConfig(v any) error {
+	data, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return err
+	}
+	path := bp.configPath()
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func configError(path string, data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, col := configPosition(data, syntaxErr.Offset)
+		return fmt.Errorf("%s:%d:%d: %w", path, line, col, err)
+	case errors.As(err, &typeErr):
+		line, col := configPosition(data, typeErr.Offset)
+		return fmt.Errorf("%s:%d:%d: 字段 %s 应为 %s, 实际为 %s", path, line, col, typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+	// 未知字段等错误已包含字段名
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+func configPosition(data []byte, offset int64) (line int, col int) {
+	offset = min(offset, int64(len(data)))
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
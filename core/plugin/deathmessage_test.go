@@ -0,0 +1,60 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import "testing"
+
+func TestParseDeathMessage(t *testing.T) {
+	samples := []struct {
+		log    string
+		player string
+		cause  string
+		killer string
+		item   string
+	}{
+		{"[12:00:00] [Server thread/INFO]: Steve drowned", "Steve", "death.attack.drown", "", ""},
+		{"[12:00:00] [Server thread/INFO]: Steve hit the ground too hard", "Steve", "death.attack.fall", "", ""},
+		{"[12:00:00] [Server thread/INFO]: Steve fell from a high place", "Steve", "death.fell.accident.generic", "", ""},
+		{"[12:00:00] [Server thread/INFO]: Steve was slain by Zombie", "Steve", "death.attack.mob", "Zombie", ""},
+		{"[12:00:00] [Server thread/INFO]: Steve was slain by Bob the Zombie", "Steve", "death.attack.mob", "Bob the Zombie", ""},
+		{"[12:00:00] [Server thread/INFO]: Steve was slain by Alex using [Diamond Sword]", "Steve", "death.attack.mob.item", "Alex", "Diamond Sword"},
+		{"[12:00:00] [Server thread/INFO]: Steve was slain by Alex using [Excalibur of Doom]", "Steve", "death.attack.mob.item", "Alex", "Excalibur of Doom"},
+		{"[12:00:00] [Server thread/INFO]: Steve was shot by Skeleton", "Steve", "death.attack.arrow", "Skeleton", ""},
+		{"[12:00:00] [Server thread/INFO]: Steve was shot by a skull from Wither", "Steve", "death.attack.witherSkull", "Wither", ""},
+		{"[12:00:00] [Server thread/INFO]: Steve was blown up by Creeper", "Steve", "death.attack.explosion.player", "Creeper", ""},
+		{"[12:00:00] [Server thread/INFO]: Steve was killed by Witch using magic", "Steve", "death.attack.indirectMagic", "Witch", ""},
+		{"[12:00:00] [Server thread/INFO]: Steve tried to swim in lava to escape Blaze", "Steve", "death.attack.lava.player", "Blaze", ""},
+		{"[12:00:00] [Server thread/INFO]: Steve went off with a bang due to a firework fired from [Rocket] by Alex", "Steve", "death.attack.fireworks.item", "Alex", "Rocket"},
+		{"[12:00:00] [Server thread/INFO]: Steve fell too far and was finished by Alex using [Bow]", "Steve", "death.fell.finish.item", "Alex", "Bow"},
+	}
+	for _, sample := range samples {
+		player, cause, killer, item, ok := ParseDeathMessage(sample.log)
+		if !ok {
+			t.Errorf("未能解析: %s", sample.log)
+			continue
+		}
+		if player != sample.player || cause != sample.cause || killer != sample.killer || item != sample.item {
+			t.Errorf("%s: 得到 (%q, %q, %q, %q), 应为 (%q, %q, %q, %q)", sample.log, player, cause, killer, item, sample.player, sample.cause, sample.killer, sample.item)
+		}
+	}
+	for _, log := range []string{
+		"[12:00:00] [Server thread/INFO]: <Steve> I drowned",
+		"[12:00:00] [Server thread/INFO]: Steve joined the game",
+	} {
+		if _, _, _, _, ok := ParseDeathMessage(log); ok {
+			t.Errorf("误识别为死亡消息: %s", log)
+		}
+	}
+}
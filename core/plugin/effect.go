@@ -0,0 +1,90 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var SoundSource = []string{"master", "music", "record", "weather", "block", "hostile", "neutral", "player", "ambient", "voice"}
+
+func (bp *BasePlugin) PlaySound(target string, sound string, source string, x float64, y float64, z float64, volume float64, pitch float64) error {
+	return bp.playSound(target, sound, source, fmt.Sprintf("%f %f %f %f %f", x, y, z, volume, pitch))
+}
+
+// 在目标玩家所在位置播放
+func (bp *BasePlugin) PlaySoundToPlayer(target string, sound string) error {
+	return bp.playSound(target, sound, "master", "")
+}
+
+func (bp *BasePlugin) playSound(target string, sound string, source string, args string) error {
+	if !slices.Contains(SoundSource, source) {
+		return fmt.Errorf("invalid sound source: %s", source)
+	}
+	if !ResourceLocation.MatchString(sound) {
+		return fmt.Errorf("invalid sound: %s", sound)
+	}
+	res := bp.RunCommand(strings.TrimSpace(fmt.Sprintf("playsound %s %s %s %s", sound, source, target, args)))
+	if !strings.Contains(res, "Played sound") {
+		if res == "" {
+			return fmt.Errorf("playsound failed")
+		}
+		return fmt.Errorf("playsound failed: %s", res)
+	}
+	return nil
+}
+
+func (bp *BasePlugin) Particle(particle string, x float64, y float64, z float64, dx float64, dy float64, dz float64, speed float64, count int, force bool, target string) error {
+	return bp.particle("", particle, x, y, z, dx, dy, dz, speed, count, force, target)
+}
+
+// 在指定维度的位置显示粒子, 可配合 PlayerInfo 的位置使用
+func (bp *BasePlugin) ParticleAt(particle string, pos *MinecraftPosition, dx float64, dy float64, dz float64, speed float64, count int, force bool, target string) error {
+	if pos == nil {
+		return fmt.Errorf("no position")
+	}
+	return bp.particle(pos.Dimension, particle, pos.Position[0], pos.Position[1], pos.Position[2], dx, dy, dz, speed, count, force, target)
+}
+
+func (bp *BasePlugin) particle(dimension string, particle string, x float64, y float64, z float64, dx float64, dy float64, dz float64, speed float64, count int, force bool, target string) error {
+	if count < 0 || speed < 0 {
+		return fmt.Errorf("invalid particle count or speed: %d %f", count, speed)
+	}
+	// 部分粒子带有参数, 如 minecraft:dust 1 0 0 1
+	if particleId, _, _ := strings.Cut(particle, " "); !ResourceLocation.MatchString(particleId) {
+		return fmt.Errorf("invalid particle: %s", particle)
+	}
+	mode := "normal"
+	if force {
+		mode = "force"
+	}
+	command := fmt.Sprintf("particle %s %f %f %f %f %f %f %f %d %s %s", particle, x, y, z, dx, dy, dz, speed, count, mode, target)
+	if dimension != "" {
+		if !ResourceLocation.MatchString(dimension) {
+			return fmt.Errorf("invalid dimension: %s", dimension)
+		}
+		command = fmt.Sprintf("execute in %s run %s", dimension, command)
+	}
+	res := bp.RunCommand(strings.TrimSpace(command))
+	if !strings.Contains(res, "Displaying particle") {
+		if res == "" {
+			return fmt.Errorf("particle failed")
+		}
+		return fmt.Errorf("particle failed: %s", res)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import "fmt"
+
+const (
+	EventPlayerJoin  = "player.join"
+	EventPlayerLeave = "player.leave"
+	EventPlayerDeath = "player.death"
+	EventPlayerChat  = "player.chat"
+	EventLagAlert    = "server.lag"
+)
+
+type PlayerChatEvent struct {
+	Player      string
+	DisplayName string
+	Message     string
+}
+
+type LagAlertEvent struct {
+	Increase bool
+	World    string
+	MSPT     float64
+	TPS      float64
+	Slope    float64
+}
+
+type PlayerDeathEvent struct {
+	Player string
+	Cause  string
+	Killer string
+}
+
+func (bp *BasePlugin) Publish(topic string, payload any) {
+	bp.pm.Publish(topic, payload)
+}
+
+func (bp *BasePlugin) Subscribe(topic string, handler func(payload any)) {
+	bp.pm.Subscribe(bp.p, topic, handler)
+}
+
+// 未指定 topic 时取消全部订阅, 在 Start 中订阅的插件需在 Pause 中取消
+func (bp *BasePlugin) Unsubscribe(topics ...string) {
+	bp.pm.Unsubscribe(bp.p, topics...)
+}
+
+func (bp *BasePlugin) subscribePlayer(topic string, handler func(player string)) {
+	bp.Subscribe(topic, func(payload any) {
+		player, ok := payload.(string)
+		if !ok {
+			panic(fmt.Errorf("unexpected payload %T for %s", payload, topic))
+		}
+		handler(player)
+	})
+}
+
+func (bp *BasePlugin) SubscribePlayerJoin(handler func(player string)) {
+	bp.subscribePlayer(EventPlayerJoin, handler)
+}
+
+func (bp *BasePlugin) SubscribePlayerLeave(handler func(player string)) {
+	bp.subscribePlayer(EventPlayerLeave, handler)
+}
+
+func (bp *BasePlugin) SubscribePlayerDeath(handler func(event PlayerDeathEvent)) {
+	bp.Subscribe(EventPlayerDeath, func(payload any) {
+		event, ok := payload.(PlayerDeathEvent)
+		if !ok {
+			panic(fmt.Errorf("unexpected payload %T for %s", payload, EventPlayerDeath))
+		}
+		handler(event)
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/manager"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"google.golang.org/grpc"
+)
+
+// 测试用的插件管理器, 未实现的方法调用时 panic
+type testPluginManager struct {
+	pluginabi.PluginManager
+	t        *testing.T
+	dir      string
+	plugins  map[string]pluginabi.Plugin
+	commands []string
+	respond  func(cmd string) string
+	stopped  bool
+	lock     sync.Mutex
+}
+
+// 切换到临时目录, 配置文件等相对路径写入其中
+func newTestPluginManager(t *testing.T) *testPluginManager {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return &testPluginManager{t: t, dir: dir, plugins: make(map[string]pluginabi.Plugin)}
+}
+
+func (pm *testPluginManager) Printf(scope string, format string, a ...any) (int, error) {
+	pm.t.Logf("[%s] %s", scope, fmt.Sprintf(format, a...))
+	return 0, nil
+}
+
+func (pm *testPluginManager) Println(scope string, a ...any) (int, error) {
+	pm.t.Log(append([]any{"[" + scope + "]"}, a...)...)
+	return 0, nil
+}
+
+func (pm *testPluginManager) RegisterLogProcesser(context pluginabi.PluginName, process func(logmsg string, iscommandrespone bool)) chan *manager.MessageResponse {
+	return nil
+}
+
+func (pm *testPluginManager) RegisterStructuredLogProcesser(context pluginabi.PluginName, filter pluginabi.LogFilter, process func(line pluginabi.LogLine)) {
+}
+
+func (pm *testPluginManager) RegisterPlugin(plugin pluginabi.Plugin) (pluginabi.Plugin, error) {
+	if err := plugin.Init(pm); err != nil {
+		return nil, err
+	}
+	pm.lock.Lock()
+	pm.plugins[plugin.Name()] = plugin
+	pm.lock.Unlock()
+	return plugin, nil
+}
+
+func (pm *testPluginManager) GetPlugin(pluginName string) pluginabi.Plugin {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+	return pm.plugins[pluginName]
+}
+
+func (pm *testPluginManager) PluginDisabled(pluginName string) bool {
+	return false
+}
+
+func (pm *testPluginManager) RunCommand(cmd string) string {
+	pm.lock.Lock()
+	pm.commands = append(pm.commands, cmd)
+	respond := pm.respond
+	pm.lock.Unlock()
+	if respond == nil {
+		return ""
+	}
+	return respond(cmd)
+}
+
+// 返回并清空已执行的命令
+func (pm *testPluginManager) takeCommands() []string {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+	commands := pm.commands
+	pm.commands = nil
+	return commands
+}
+
+func (pm *testPluginManager) DataPath(name string) string {
+	return filepath.Join(pm.dir, name)
+}
+
+func (pm *testPluginManager) Publish(topic string, payload any) {}
+
+func (pm *testPluginManager) Subscribe(context pluginabi.PluginName, topic string, handler func(payload any)) {
+}
+
+func (pm *testPluginManager) Unsubscribe(context pluginabi.PluginName, topics ...string) {}
+
+func (pm *testPluginManager) ServerInfo() pluginabi.ServerInfo {
+	return pluginabi.ServerInfo{Flavor: pluginabi.ServerFlavorVanilla}
+}
+
+func (pm *testPluginManager) Status(opts ...grpc.CallOption) (*manager.StatusResponse, error) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+	if pm.stopped {
+		return &manager.StatusResponse{State: manager.MinecraftState_stopped}, nil
+	}
+	return &manager.StatusResponse{State: manager.MinecraftState_running}, nil
+}
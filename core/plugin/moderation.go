@@ -0,0 +1,171 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrPlayerNotFound     = errors.New("player not found")
+	ErrAlreadyWhitelisted = errors.New("player already whitelisted")
+	ErrNotWhitelisted     = errors.New("player not whitelisted")
+	ErrAlreadyBanned      = errors.New("player already banned")
+	ErrNotBanned          = errors.New("player not banned")
+)
+
+var (
+	ModerationPlayerNotFound = regexp.MustCompile(`That player does not exist`)
+	WhitelistAdded           = regexp.MustCompile(`Added (\S+) to the whitelist`)
+	WhitelistAlreadyAdded    = regexp.MustCompile(`Player is already whitelisted`)
+	WhitelistRemoved         = regexp.MustCompile(`Removed (\S+) from the whitelist`)
+	WhitelistNotAdded        = regexp.MustCompile(`Player is not whitelisted`)
+	WhitelistList            = regexp.MustCompile(`There are \d+ whitelisted player(?:\(s\)|s)?: (.*)`)
+	WhitelistEmpty           = regexp.MustCompile(`There are no whitelisted players`)
+	BanAdded                 = regexp.MustCompile(`Banned (\S+)`)
+	BanAlreadyBanned         = regexp.MustCompile(`The player is already banned`)
+	BanRemoved               = regexp.MustCompile(`Unbanned (\S+)`)
+	BanNotBanned             = regexp.MustCompile(`The player isn't banned`)
+	BanListEntry             = regexp.MustCompile(`^(\S+) was banned by (.*?): (.*)$`)
+	BanListHeader            = regexp.MustCompile(`There (?:are \d+ ban\(s\)|are no bans)`)
+)
+
+type BanEntry struct {
+	Player string
+	Source string
+	Reason string
+}
+
+// 玩家名会直接拼入命令, 不允许包含空白字符
+func moderationCheckPlayer(player string) error {
+	if player == "" || strings.ContainsAny(player, " \t\r\n") {
+		return fmt.Errorf("无效的玩家名: %q", player)
+	}
+	return nil
+}
+
+func moderationResult(response string, success *regexp.Regexp, known map[*regexp.Regexp]error) error {
+	if success.MatchString(response) {
+		return nil
+	}
+	if ModerationPlayerNotFound.MatchString(response) {
+		return ErrPlayerNotFound
+	}
+	for re, err := range known {
+		if re.MatchString(response) {
+			return err
+		}
+	}
+	return fmt.Errorf("unexpected response: %s", response)
+}
+
+func (bp *BasePlugin) AddWhitelist(player string) error {
+	if err := moderationCheckPlayer(player); err != nil {
+		return err
+	}
+	response := bp.RunCommand("whitelist add " + player)
+	return moderationResult(response, WhitelistAdded, map[*regexp.Regexp]error{WhitelistAlreadyAdded: ErrAlreadyWhitelisted})
+}
+
+func (bp *BasePlugin) RemoveWhitelist(player string) error {
+	if err := moderationCheckPlayer(player); err != nil {
+		return err
+	}
+	response := bp.RunCommand("whitelist remove " + player)
+	return moderationResult(response, WhitelistRemoved, map[*regexp.Regexp]error{WhitelistNotAdded: ErrNotWhitelisted})
+}
+
+func (bp *BasePlugin) ListWhitelist() ([]string, error) {
+	response := bp.RunCommand("whitelist list")
+	if WhitelistEmpty.MatchString(response) {
+		return []string{}, nil
+	}
+	match := WhitelistList.FindStringSubmatch(response)
+	if len(match) != 2 {
+		return nil, fmt.Errorf("unexpected response: %s", response)
+	}
+	return strings.Split(match[1], ", "), nil
+}
+
+// reason 为空时使用服务端默认原因
+func (bp *BasePlugin) Ban(player string, reason string) error {
+	if err := moderationCheckPlayer(player); err != nil {
+		return err
+	}
+	command := "ban " + player
+	if reason = strings.TrimSpace(strings.ReplaceAll(reason, "\n", " ")); reason != "" {
+		command += " " + reason
+	}
+	response := bp.RunCommand(command)
+	return moderationResult(response, BanAdded, map[*regexp.Regexp]error{BanAlreadyBanned: ErrAlreadyBanned})
+}
+
+func (bp *BasePlugin) Pardon(player string) error {
+	if err := moderationCheckPlayer(player); err != nil {
+		return err
+	}
+	response := bp.RunCommand("pardon " + player)
+	return moderationResult(response, BanRemoved, map[*regexp.Regexp]error{BanNotBanned: ErrNotBanned})
+}
+
+func (bp *BasePlugin) ListBans() ([]BanEntry, error) {
+	response := bp.RunCommand("banlist players")
+	if !BanListHeader.MatchString(response) {
+		return nil, fmt.Errorf("unexpected response: %s", response)
+	}
+	// 原版 RCON 拼接多行输出时不加换行, 无法区分原因与下一条记录, 改为读取服务端的封禁文件
+	if !strings.Contains(response, "\n") && strings.Count(response, " was banned by ") > 1 {
+		return bp.readBannedPlayers()
+	}
+	bans := []BanEntry{}
+	for _, line := range strings.Split(response, "\n") {
+		match := BanListEntry.FindStringSubmatch(line)
+		if len(match) == 4 {
+			bans = append(bans, BanEntry{Player: match[1], Source: match[2], Reason: match[3]})
+		}
+	}
+	return bans, nil
+}
+
+// 服务端目录为世界目录的上级目录
+func (bp *BasePlugin) readBannedPlayers() ([]BanEntry, error) {
+	if bp.playerInfo == nil {
+		return nil, fmt.Errorf("no playerInfo instance")
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(bp.playerInfo.WorldDir), "banned-players.json"))
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 RCON 返回的封禁列表, 读取封禁文件失败: %w", err)
+	}
+	entries := []struct {
+		Name   string `json:"name"`
+		Source string `json:"source"`
+		Reason string `json:"reason"`
+	}{}
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("解析封禁文件失败: %w", err)
+	}
+	bans := make([]BanEntry, 0, len(entries))
+	for _, entry := range entries {
+		bans = append(bans, BanEntry{Player: entry.Name, Source: entry.Source, Reason: entry.Reason})
+	}
+	return bans, nil
+}
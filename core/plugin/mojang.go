@@ -0,0 +1,126 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrMojangProfileNotFound = errors.New("mojang profile not found")
+
+const (
+	MojangProfileAPI = "https://api.mojang.com/users/profiles/minecraft/"
+	MojangSessionAPI = "https://sessionserver.mojang.com/session/minecraft/profile/"
+)
+
+type mojangProfile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type mojangCacheEntry struct {
+	profile *mojangProfile
+	expire  time.Time
+}
+
+type MojangResolver struct {
+	Timeout time.Duration
+	TTL     time.Duration
+	client  *http.Client
+	cache   map[string]*mojangCacheEntry
+	lock    sync.Mutex
+}
+
+func NewMojangResolver(timeout time.Duration, ttl time.Duration) *MojangResolver {
+	return &MojangResolver{Timeout: timeout, TTL: ttl}
+}
+
+func (mr *MojangResolver) init() {
+	if mr.client == nil {
+		mr.client = &http.Client{Timeout: mr.Timeout}
+	}
+	if mr.cache == nil {
+		mr.cache = make(map[string]*mojangCacheEntry)
+	}
+}
+
+func (mr *MojangResolver) formatUUID(id string) string {
+	id = strings.ReplaceAll(id, "-", "")
+	if len(id) != 32 {
+		return id
+	}
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:])
+}
+
+func (mr *MojangResolver) query(cacheKey string, api string) (*mojangProfile, error) {
+	mr.lock.Lock()
+	mr.init()
+	entry, ok := mr.cache[cacheKey]
+	mr.lock.Unlock()
+	if ok && time.Now().Before(entry.expire) {
+		if entry.profile == nil {
+			return nil, ErrMojangProfileNotFound
+		}
+		return entry.profile, nil
+	}
+	resp, err := mr.client.Get(api)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var profile *mojangProfile
+	switch resp.StatusCode {
+	case http.StatusOK:
+		profile = &mojangProfile{}
+		err = json.NewDecoder(resp.Body).Decode(profile)
+		if err != nil {
+			return nil, err
+		}
+	case http.StatusNoContent, http.StatusNotFound:
+	default:
+		return nil, fmt.Errorf("mojang api: %s", resp.Status)
+	}
+	mr.lock.Lock()
+	mr.cache[cacheKey] = &mojangCacheEntry{profile: profile, expire: time.Now().Add(mr.TTL)}
+	mr.lock.Unlock()
+	if profile == nil {
+		return nil, ErrMojangProfileNotFound
+	}
+	return profile, nil
+}
+
+func (mr *MojangResolver) GetUUID(player string) (uuid string, err error) {
+	profile, err := mr.query("name:"+strings.ToLower(player), MojangProfileAPI+url.PathEscape(player))
+	if err != nil {
+		return "", err
+	}
+	return mr.formatUUID(profile.ID), nil
+}
+
+func (mr *MojangResolver) GetName(uuid string) (player string, err error) {
+	uuid = strings.ReplaceAll(uuid, "-", "")
+	profile, err := mr.query("uuid:"+strings.ToLower(uuid), MojangSessionAPI+url.PathEscape(uuid))
+	if err != nil {
+		return "", err
+	}
+	return profile.Name, nil
+}
@@ -0,0 +1,231 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbt
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+type TagType byte
+
+const (
+	TagEnd TagType = iota
+	TagByte
+	TagShort
+	TagInt
+	TagLong
+	TagFloat
+	TagDouble
+	TagByteArray
+	TagString
+	TagList
+	TagCompound
+	TagIntArray
+	TagLongArray
+)
+
+type Compound map[string]any
+
+const maxDepth = 512
+
+type decoder struct {
+	r     *bufio.Reader
+	depth int
+}
+
+// 读取 gzip 压缩的 NBT 文件 (playerdata/*.dat, level.dat)
+func ReadFile(path string) (Compound, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return Decode(gz)
+}
+
+func Decode(r io.Reader) (Compound, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+	tagType, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	if TagType(tagType) != TagCompound {
+		return nil, fmt.Errorf("root tag is not compound")
+	}
+	_, err = d.readString()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := d.readPayload(TagCompound)
+	if err != nil {
+		return nil, err
+	}
+	return payload.(Compound), nil
+}
+
+func (d *decoder) readByte() (byte, error) {
+	return d.r.ReadByte()
+}
+
+func (d *decoder) readString() (string, error) {
+	var length uint16
+	err := binary.Read(d.r, binary.BigEndian, &length)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, length)
+	_, err = io.ReadFull(d.r, buf)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func (d *decoder) readLength() (int, error) {
+	var length int32
+	err := binary.Read(d.r, binary.BigEndian, &length)
+	if err != nil {
+		return 0, err
+	}
+	if length < 0 {
+		return 0, fmt.Errorf("negative array length")
+	}
+	return int(length), nil
+}
+
+// 数组长度来自文件, 按块读取, 损坏或截断的文件不会按声明的长度一次性分配内存
+func readArray[T int8 | int32 | int64](d *decoder, length int) ([]T, error) {
+	buf := make([]T, 0, min(length, 4096))
+	chunk := make([]T, min(length, 4096))
+	for len(buf) < length {
+		n := min(length-len(buf), len(chunk))
+		err := binary.Read(d.r, binary.BigEndian, chunk[:n])
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, chunk[:n]...)
+	}
+	return buf, nil
+}
+
+func (d *decoder) readPayload(tagType TagType) (v any, err error) {
+	switch tagType {
+	case TagByte:
+		var b int8
+		err = binary.Read(d.r, binary.BigEndian, &b)
+		return b, err
+	case TagShort:
+		var s int16
+		err = binary.Read(d.r, binary.BigEndian, &s)
+		return s, err
+	case TagInt:
+		var i int32
+		err = binary.Read(d.r, binary.BigEndian, &i)
+		return i, err
+	case TagLong:
+		var l int64
+		err = binary.Read(d.r, binary.BigEndian, &l)
+		return l, err
+	case TagFloat:
+		var f uint32
+		err = binary.Read(d.r, binary.BigEndian, &f)
+		return math.Float32frombits(f), err
+	case TagDouble:
+		var f uint64
+		err = binary.Read(d.r, binary.BigEndian, &f)
+		return math.Float64frombits(f), err
+	case TagByteArray:
+		length, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		return readArray[int8](d, length)
+	case TagString:
+		return d.readString()
+	case TagList:
+		d.depth++
+		defer func() { d.depth-- }()
+		if d.depth > maxDepth {
+			return nil, fmt.Errorf("nbt nested too deep")
+		}
+		elemType, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		length, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]any, 0, min(length, 4096))
+		for i := 0; i < length; i++ {
+			elem, err := d.readPayload(TagType(elemType))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, elem)
+		}
+		return list, nil
+	case TagCompound:
+		d.depth++
+		defer func() { d.depth-- }()
+		if d.depth > maxDepth {
+			return nil, fmt.Errorf("nbt nested too deep")
+		}
+		compound := make(Compound)
+		for {
+			childType, err := d.readByte()
+			if err != nil {
+				return nil, err
+			}
+			if TagType(childType) == TagEnd {
+				return compound, nil
+			}
+			name, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			compound[name], err = d.readPayload(TagType(childType))
+			if err != nil {
+				return nil, err
+			}
+		}
+	case TagIntArray:
+		length, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		return readArray[int32](d, length)
+	case TagLongArray:
+		length, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		return readArray[int64](d, length)
+	case TagEnd:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown tag type %d", tagType)
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// 构造测试用的 NBT 数据
+type testEncoder struct {
+	bytes.Buffer
+}
+
+func (e *testEncoder) write(v any) *testEncoder {
+	binary.Write(&e.Buffer, binary.BigEndian, v)
+	return e
+}
+
+func (e *testEncoder) tag(tagType TagType, name string) *testEncoder {
+	return e.write(byte(tagType)).str(name)
+}
+
+func (e *testEncoder) str(s string) *testEncoder {
+	e.write(uint16(len(s)))
+	e.WriteString(s)
+	return e
+}
+
+func testPlayerData() []byte {
+	e := &testEncoder{}
+	e.tag(TagCompound, "")
+	e.tag(TagByte, "OnGround").write(int8(1))
+	e.tag(TagShort, "Fire").write(int16(-20))
+	e.tag(TagInt, "XpLevel").write(int32(30))
+	e.tag(TagLong, "WorldUUIDMost").write(int64(-42))
+	e.tag(TagFloat, "Health").write(math.Float32bits(20))
+	e.tag(TagString, "Dimension").str("minecraft:overworld")
+	e.tag(TagList, "Pos").write(byte(TagDouble)).write(int32(3))
+	e.write(math.Float64bits(1.5)).write(math.Float64bits(64)).write(math.Float64bits(-3.25))
+	e.tag(TagIntArray, "UUID").write(int32(4)).write([]int32{1, 2, 3, 4})
+	e.tag(TagByteArray, "Bytes").write(int32(2)).write([]int8{-1, 1})
+	e.tag(TagLongArray, "Longs").write(int32(1)).write([]int64{1 << 40})
+	e.tag(TagCompound, "abilities")
+	e.tag(TagByte, "flying").write(int8(0))
+	e.write(byte(TagEnd))
+	e.write(byte(TagEnd))
+	return e.Bytes()
+}
+
+func TestReadFile(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write(testPlayerData())
+	w.Close()
+	path := filepath.Join(t.TempDir(), "player.dat")
+	if err := os.WriteFile(path, gz.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Compound{
+		"OnGround":      int8(1),
+		"Fire":          int16(-20),
+		"XpLevel":       int32(30),
+		"WorldUUIDMost": int64(-42),
+		"Health":        float32(20),
+		"Dimension":     "minecraft:overworld",
+		"Pos":           []any{1.5, 64.0, -3.25},
+		"UUID":          []int32{1, 2, 3, 4},
+		"Bytes":         []int8{-1, 1},
+		"Longs":         []int64{1 << 40},
+		"abilities":     Compound{"flying": int8(0)},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("解析结果为 %#v, 应为 %#v", data, expected)
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	data := testPlayerData()
+	for _, n := range []int{0, 1, 5, len(data) / 2, len(data) - 1} {
+		if _, err := Decode(bytes.NewReader(data[:n])); err == nil {
+			t.Errorf("截断为 %d 字节时应返回错误", n)
+		}
+	}
+}
+
+// 声明的数组长度远大于实际数据时应返回错误, 而不是按声明的长度分配内存
+func TestDecodeOversizedArray(t *testing.T) {
+	for _, tagType := range []TagType{TagByteArray, TagIntArray, TagLongArray, TagList} {
+		e := &testEncoder{}
+		e.tag(TagCompound, "")
+		e.tag(tagType, "Data")
+		if tagType == TagList {
+			e.write(byte(TagInt))
+		}
+		e.write(int32(math.MaxInt32)).write([]int32{1, 2, 3})
+		if _, err := Decode(bytes.NewReader(e.Bytes())); err == nil {
+			t.Errorf("类型 %d 的超长数组应返回错误", tagType)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	snbtByte   = regexp.MustCompile(`^[-+]?\d+[bB]$`)
+	snbtShort  = regexp.MustCompile(`^[-+]?\d+[sS]$`)
+	snbtInt    = regexp.MustCompile(`^[-+]?\d+$`)
+	snbtLong   = regexp.MustCompile(`^[-+]?\d+[lL]$`)
+	snbtFloat  = regexp.MustCompile(`^[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?[fF]$`)
+	snbtDouble = regexp.MustCompile(`^[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?[dD]?$`)
+)
+
+type snbtParser struct {
+	s     string
+	pos   int
+	depth int
+}
+
+// 解析 data get 等命令输出的 SNBT 文本, 返回值类型与 Decode 一致
+func ParseSNBT(s string) (any, error) {
+	p := &snbtParser{s: s}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, p.errorf("unexpected trailing data")
+	}
+	return value, nil
+}
+
+func (p *snbtParser) errorf(format string, a ...any) error {
+	return fmt.Errorf("snbt: %s at offset %d", fmt.Sprintf(format, a...), p.pos)
+}
+
+func (p *snbtParser) skipSpace() {
+	for p.pos < len(p.s) && strings.ContainsRune(" \t\r\n", rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *snbtParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *snbtParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *snbtParser) parseValue() (any, error) {
+	switch p.peek() {
+	case '{':
+		return p.parseCompound()
+	case '[':
+		return p.parseList()
+	case '"', '\'':
+		return p.parseQuoted()
+	case 0:
+		return nil, p.errorf("unexpected end of data")
+	}
+	token := p.parseUnquoted()
+	if token == "" {
+		return nil, p.errorf("unexpected character %q", p.s[p.pos])
+	}
+	return parseSNBTPrimitive(token), nil
+}
+
+func parseSNBTPrimitive(token string) any {
+	switch {
+	case snbtByte.MatchString(token):
+		if v, err := strconv.ParseInt(token[:len(token)-1], 10, 8); err == nil {
+			return int8(v)
+		}
+	case snbtShort.MatchString(token):
+		if v, err := strconv.ParseInt(token[:len(token)-1], 10, 16); err == nil {
+			return int16(v)
+		}
+	case snbtLong.MatchString(token):
+		if v, err := strconv.ParseInt(token[:len(token)-1], 10, 64); err == nil {
+			return v
+		}
+	case snbtInt.MatchString(token):
+		if v, err := strconv.ParseInt(token, 10, 32); err == nil {
+			return int32(v)
+		}
+	case snbtFloat.MatchString(token):
+		if v, err := strconv.ParseFloat(token[:len(token)-1], 32); err == nil {
+			return float32(v)
+		}
+	case snbtDouble.MatchString(token):
+		if v, err := strconv.ParseFloat(strings.TrimRight(token, "dD"), 64); err == nil {
+			return v
+		}
+	case token == "true":
+		return int8(1)
+	case token == "false":
+		return int8(0)
+	}
+	return token
+}
+
+func isSNBTUnquoted(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || strings.IndexByte("_-.+", c) >= 0
+}
+
+func (p *snbtParser) parseUnquoted() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && isSNBTUnquoted(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *snbtParser) parseQuoted() (string, error) {
+	quote := p.s[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+		switch c {
+		case '\\':
+			if p.pos >= len(p.s) {
+				return "", p.errorf("unterminated string")
+			}
+			sb.WriteByte(p.s[p.pos])
+			p.pos++
+		case quote:
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *snbtParser) parseKey() (string, error) {
+	switch p.peek() {
+	case '"', '\'':
+		return p.parseQuoted()
+	}
+	key := p.parseUnquoted()
+	if key == "" {
+		return "", p.errorf("expected key")
+	}
+	return key, nil
+}
+
+func (p *snbtParser) enter() error {
+	p.depth++
+	if p.depth > maxDepth {
+		return p.errorf("nesting too deep")
+	}
+	return nil
+}
+
+func (p *snbtParser) parseCompound() (Compound, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	p.pos++
+	compound := Compound{}
+	if p.peek() == '}' {
+		p.pos++
+		return compound, nil
+	}
+	for {
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(':'); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		compound[key] = value
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return compound, nil
+		default:
+			return nil, p.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+func (p *snbtParser) parseList() (any, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	p.pos++
+	arrayType := byte(0)
+	if p.pos+1 < len(p.s) && p.s[p.pos+1] == ';' && strings.IndexByte("BIL", p.s[p.pos]) >= 0 {
+		arrayType = p.s[p.pos]
+		p.pos += 2
+	}
+	values := []any{}
+	if p.peek() == ']' {
+		p.pos++
+	} else {
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if p.peek() == ',' {
+				p.pos++
+				continue
+			}
+			if err = p.expect(']'); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	switch arrayType {
+	case 'B':
+		array := make([]int8, len(values))
+		for i, v := range values {
+			b, ok := v.(int8)
+			if !ok {
+				return nil, p.errorf("invalid byte array element")
+			}
+			array[i] = b
+		}
+		return array, nil
+	case 'I':
+		array := make([]int32, len(values))
+		for i, v := range values {
+			n, ok := v.(int32)
+			if !ok {
+				return nil, p.errorf("invalid int array element")
+			}
+			array[i] = n
+		}
+		return array, nil
+	case 'L':
+		array := make([]int64, len(values))
+		for i, v := range values {
+			switch n := v.(type) {
+			case int64:
+				array[i] = n
+			case int32:
+				array[i] = int64(n)
+			default:
+				return nil, p.errorf("invalid long array element")
+			}
+		}
+		return array, nil
+	}
+	return values, nil
+}
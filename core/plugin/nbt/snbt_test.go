@@ -0,0 +1,115 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSNBT(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected any
+	}{
+		{"1b", int8(1)},
+		{"-3B", int8(-3)},
+		{"300b", "300b"},
+		{"12s", int16(12)},
+		{"-7S", int16(-7)},
+		{"7", int32(7)},
+		{"2147483648", "2147483648"},
+		{"5L", int64(5)},
+		{"-9000000000l", int64(-9000000000)},
+		{"1.5f", float32(1.5)},
+		{".5F", float32(0.5)},
+		{"2.5d", 2.5},
+		{"2.5", 2.5},
+		{"1e3D", 1000.0},
+		{"true", int8(1)},
+		{"false", int8(0)},
+		{"minecraft", "minecraft"},
+		{"[B; 1b, -2b]", []int8{1, -2}},
+		{"[I;1,2,3]", []int32{1, 2, 3}},
+		{"[L; 1L, 2]", []int64{1, 2}},
+		{"[I;]", []int32{}},
+		{"[1, 2b, a]", []any{int32(1), int8(2), "a"}},
+		{"[]", []any{}},
+		{`"a\"b\\c"`, `a"b\c`},
+		{`'it\'s "quoted"'`, `it's "quoted"`},
+		{`{"minecraft:custom_name": 1, 'a b': 2b, plain: {}}`, Compound{"minecraft:custom_name": int32(1), "a b": int8(2), "plain": Compound{}}},
+		{" { a : [ ] } ", Compound{"a": []any{}}},
+	}
+	for _, c := range cases {
+		value, err := ParseSNBT(c.input)
+		if err != nil {
+			t.Errorf("ParseSNBT(%q) 失败: %v", c.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, c.expected) {
+			t.Errorf("ParseSNBT(%q) = %#v, 应为 %#v", c.input, value, c.expected)
+		}
+	}
+}
+
+// data get entity <player> Inventory 的实际输出
+func TestParseSNBTInventory(t *testing.T) {
+	outputs := []string{
+		`Steve has the following entity data: [{Slot: 0b, id: "minecraft:diamond_sword", Count: 1b, tag: {Damage: 5, display: {Name: '{"text":"Excalibur"}'}, Enchantments: [{id: "minecraft:sharpness", lvl: 5s}]}}, {Slot: 1b, id: "minecraft:stone", Count: 64b}]`,
+		`Steve has the following entity data: [{count: 1, Slot: 0b, components: {"minecraft:damage": 5, "minecraft:custom_name": '{"text":"Excalibur"}', "minecraft:enchantments": {levels: {"minecraft:sharpness": 5}}}, id: "minecraft:diamond_sword"}, {count: 64, Slot: 1b, id: "minecraft:stone"}]`,
+	}
+	for _, output := range outputs {
+		_, data, _ := strings.Cut(output, ":")
+		value, err := ParseSNBT(data)
+		if err != nil {
+			t.Errorf("解析 %q 失败: %v", output, err)
+			continue
+		}
+		list, ok := value.([]any)
+		if !ok || len(list) != 2 {
+			t.Errorf("解析结果为 %#v, 应为 2 个物品", value)
+			continue
+		}
+		sword, ok := list[0].(Compound)
+		if !ok || sword["id"] != "minecraft:diamond_sword" || sword["Slot"] != int8(0) {
+			t.Errorf("第一个物品为 %#v", list[0])
+		}
+	}
+}
+
+func TestParseSNBTDepth(t *testing.T) {
+	if _, err := ParseSNBT(strings.Repeat("[", maxDepth) + strings.Repeat("]", maxDepth)); err != nil {
+		t.Errorf("%d 层嵌套解析失败: %v", maxDepth, err)
+	}
+	if _, err := ParseSNBT(strings.Repeat("[", maxDepth+1) + strings.Repeat("]", maxDepth+1)); err == nil {
+		t.Error("超出嵌套层数时应返回错误")
+	}
+	if _, err := ParseSNBT(strings.Repeat("{a:", maxDepth+1)); err == nil {
+		t.Error("超出嵌套层数时应返回错误")
+	}
+}
+
+func TestParseSNBTMalformed(t *testing.T) {
+	inputs := []string{
+		"", " ", "{", "}", "[", "]", "{a:}", "{a 1}", "{a:1", "{a:1}}", "{:1}", "{a:1,}", "[1,", "[1 2]", "[1,]",
+		`"abc`, `'abc`, `"abc\`, "[B;1,2]", "[I;1b]", "[L;1.5]", "[B;", "[I;1", "a b", "@", "{a:1}garbage", "\x00",
+	}
+	for _, input := range inputs {
+		if value, err := ParseSNBT(input); err == nil {
+			t.Errorf("ParseSNBT(%q) = %#v, 应返回错误", input, value)
+		}
+	}
+}
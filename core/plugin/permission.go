@@ -0,0 +1,113 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CommandPermission struct {
+	OpLevel    int
+	Permission string
+}
+
+type CommandOption func(perm *CommandPermission)
+
+// 需要不低于 level 的 OP 等级
+func OpLevel(level int) CommandOption {
+	return func(perm *CommandPermission) {
+		perm.OpLevel = level
+	}
+}
+
+// 需要在 config/SimpleCommand.json 中被授予 name 权限, 4 级 OP 默认拥有全部权限
+func Permission(name string) CommandOption {
+	return func(perm *CommandPermission) {
+		perm.Permission = name
+	}
+}
+
+type SimpleCommand_Config struct {
+	Permissions map[string][]string // 权限名 -> 玩家列表
+	ChatPrefix  string              // 除 !! 外额外响应的命令前缀, 如 "!", 为空时不启用
+}
+
+type playerInfo_OpEntry struct {
+	UUID  string `json:"uuid"`
+	Name  string `json:"name"`
+	Level int    `json:"level"`
+}
+
+type playerInfo_OpList struct {
+	mtime time.Time
+	ops   []playerInfo_OpEntry
+	lock  sync.Mutex
+}
+
+// 读取服务端 ops.json, 文件未变化时使用缓存
+func (pi *PlayerInfo) GetOpLevel(player string) int {
+	path := filepath.Join(filepath.Dir(pi.WorldDir), "ops.json")
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	pi.opList.lock.Lock()
+	defer pi.opList.lock.Unlock()
+	if !pi.opList.mtime.Equal(stat.ModTime()) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0
+		}
+		var ops []playerInfo_OpEntry
+		if json.Unmarshal(data, &ops) != nil {
+			return 0
+		}
+		pi.opList.ops = ops
+		pi.opList.mtime = stat.ModTime()
+	}
+	for _, op := range pi.opList.ops {
+		if strings.EqualFold(op.Name, player) {
+			return op.Level
+		}
+	}
+	return 0
+}
+
+func (sp *SimpleCommand) hasPermission(player string, perm CommandPermission) bool {
+	if perm.OpLevel <= 0 && perm.Permission == "" {
+		return true
+	}
+	opLevel := 0
+	if sp.playerInfo != nil {
+		opLevel = sp.playerInfo.GetOpLevel(player)
+	}
+	if perm.OpLevel > 0 && opLevel < perm.OpLevel {
+		return false
+	}
+	if perm.Permission != "" && opLevel < 4 {
+		sp.lock.RLock()
+		defer sp.lock.RUnlock()
+		return slices.ContainsFunc(sp.config.Permissions[perm.Permission], func(name string) bool {
+			return strings.EqualFold(name, player)
+		})
+	}
+	return true
+}
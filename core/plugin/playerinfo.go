@@ -16,20 +16,27 @@ package plugin
 
 import (
 	"bytes"
+	"crypto/md5"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
 	"github.com/fatih/color"
 	"github.com/samber/lo"
+	"golang.org/x/exp/maps"
 )
 
 type MinecraftPlayerInfo_Extra map[string]any
@@ -50,26 +57,53 @@ func (extra *MinecraftPlayerInfo_Extra) UnmarshalJSON(data []byte) error {
 }
 
 type MinecraftPlayerInfo struct {
-	Player       string
-	Location     *MinecraftPosition
-	LastLocation *MinecraftPosition
-	UUID         string
-	Extra        MinecraftPlayerInfo_Extra
-	lock         sync.RWMutex
-	playerInfo   *PlayerInfo
+	Player            string
+	DisplayName       string
+	Location          *MinecraftPosition
+	LastLocation      *MinecraftPosition
+	UUID              string
+	PlaytimeSeconds   int64
+	LastSeen          time.Time
+	Gamemode          string
+	Extra             MinecraftPlayerInfo_Extra
+	ExtraVersion      map[string]int // 各插件 Extra 数据的结构版本, 缺省为 0
+	Health            float64        // 以下字段仅由 GetPlayerInfo_Full 填充, 不持久化
+	FoodLevel         int
+	XpLevel           int
+	LocationFetchedAt time.Time // Location 最近一次从服务器获取的时间, 不持久化
+	Ping              int       // 延迟 (毫秒), 由 updatePlayerList 刷新, 不持久化
+	PingAvailable     bool      // 为 false 时玩家不在线或服务端不提供延迟
+	lock              sync.RWMutex
+	playerInfo        *PlayerInfo
 }
 
 func (mpi *MinecraftPlayerInfo) MarshalJSON() ([]byte, error) {
 	mpi.lock.RLock()
 	defer mpi.lock.RUnlock()
 	type playerinfo struct {
-		Player       string
-		Location     *MinecraftPosition
-		LastLocation *MinecraftPosition
-		UUID         string
-		Extra        MinecraftPlayerInfo_Extra
+		Player          string
+		DisplayName     string
+		Location        *MinecraftPosition
+		LastLocation    *MinecraftPosition
+		UUID            string
+		PlaytimeSeconds int64
+		LastSeen        time.Time
+		Gamemode        string
+		Extra           MinecraftPlayerInfo_Extra
+		ExtraVersion    map[string]int `json:",omitempty"`
+	}
+	pi := playerinfo{
+		Player:          mpi.Player,
+		DisplayName:     mpi.DisplayName,
+		Location:        mpi.Location,
+		LastLocation:    mpi.LastLocation,
+		UUID:            mpi.UUID,
+		PlaytimeSeconds: mpi.PlaytimeSeconds,
+		LastSeen:        mpi.LastSeen,
+		Gamemode:        mpi.Gamemode,
+		Extra:           mpi.Extra,
+		ExtraVersion:    mpi.ExtraVersion,
 	}
-	pi := playerinfo{mpi.Player, mpi.Location, mpi.LastLocation, mpi.UUID, mpi.Extra}
 	return json.Marshal(pi)
 }
 
@@ -79,28 +113,23 @@ func (mpi *MinecraftPlayerInfo) Commit() error {
 	return mpi.playerInfo.Commit(mpi)
 }
 
+// 解码到新值后缓存, 不缓存调用方的 v, 避免调用方修改 v 时与其他读取者竞争
 func (mpi *MinecraftPlayerInfo) GetExtra(context pluginabi.PluginName, v any) error {
+	target := reflect.ValueOf(v)
+	extra, ok, err := mpi.loadExtra(context.Name(), func() any {
+		return reflect.New(target.Type().Elem()).Interface()
+	})
+	if !ok || err != nil {
+		return err
+	}
+	// 缓存的值可能被 PutExtra 同时替换, 复制时需持有锁
 	mpi.lock.RLock()
-	extra, ok := mpi.Extra[context.Name()]
-	mpi.lock.RUnlock()
-	if ok {
-		switch extra := extra.(type) {
-		case json.RawMessage:
-			err := json.Unmarshal(extra, v)
-			if err != nil {
-				return err
-			}
-			mpi.lock.Lock()
-			mpi.Extra[context.Name()] = v
-			mpi.lock.Unlock()
-		default:
-			x := reflect.ValueOf(extra)
-			if x.Kind() == reflect.Ptr {
-				reflect.ValueOf(v).Elem().Set(x.Elem())
-			} else {
-				reflect.ValueOf(v).Elem().Set(x)
-			}
-		}
+	defer mpi.lock.RUnlock()
+	x := reflect.ValueOf(extra)
+	if x.Kind() == reflect.Ptr {
+		target.Elem().Set(x.Elem())
+	} else {
+		target.Elem().Set(x)
 	}
 	return nil
 }
@@ -109,8 +138,25 @@ func (mpi *MinecraftPlayerInfo) PutExtra(context pluginabi.PluginName, extra any
 	mpi.lock.Lock()
 	defer mpi.lock.Unlock()
 	mpi.Extra[context.Name()] = extra
+	mpi.setExtraVersion(context.Name(), mpi.playerInfo.extraVersion(context.Name()))
 }
 
+func (mpi *MinecraftPlayerInfo) replace(from *MinecraftPlayerInfo) {
+	mpi.lock.Lock()
+	defer mpi.lock.Unlock()
+	mpi.Player = from.Player
+	mpi.DisplayName = from.DisplayName
+	mpi.Location = from.Location
+	mpi.LastLocation = from.LastLocation
+	mpi.UUID = from.UUID
+	mpi.PlaytimeSeconds = from.PlaytimeSeconds
+	mpi.LastSeen = from.LastSeen
+	mpi.Gamemode = from.Gamemode
+	mpi.Extra = from.Extra
+	mpi.ExtraVersion = from.ExtraVersion
+}
+
+// 加锁顺序: playerInfoLock -> uuidMapLock -> MinecraftPlayerInfo.lock, 持有 MinecraftPlayerInfo.lock 时不可再获取前两者
 type PlayerInfo_Storage struct {
 	PlayerInfo     map[string]*MinecraftPlayerInfo
 	playerInfoLock sync.RWMutex
@@ -138,14 +184,117 @@ func (s *PlayerInfo_Storage) RUnlock() {
 	s.uuidMapLock.RUnlock()
 }
 
+type PlayerInfo_Mode int
+
+const (
+	PlayerInfo_ModeAuto PlayerInfo_Mode = iota
+	PlayerInfo_ModeOnline
+	PlayerInfo_ModeOffline
+)
+
 type PlayerInfo struct {
 	BasePlugin
-	playerList     []string
-	playerListLock sync.RWMutex
-	data           *PlayerInfo_Storage
+	WorldDir        string // Minecraft world dir, 用于读取离线玩家数据
+	Mojang          *MojangResolver
+	Mode            PlayerInfo_Mode
+	RefreshInterval time.Duration    // 玩家列表与位置的刷新间隔, 默认 60s
+	MaxTravelStep   float64          // 两次位置采样间移动超过该距离 (格) 视为传送, 不计入里程, 默认 1000
+	AfkTimeout      time.Duration    // 位置持续不变超过该时间视为挂机, 默认 5min, 精度受 RefreshInterval 限制
+	PositionTTL     time.Duration    // 在线玩家的位置在该时间内视为有效, 过期后访问时重新获取, 默认 5s
+	Store           PlayerInfo_Store // 默认为数据目录下的 playerinfo.json
+	offlineMode     atomic.Bool
+	playerList      []string
+	playerListReady bool // 启动后首次刷新前为 false, 首次刷新不触发加入/离开事件
+	playerListLock  sync.RWMutex
+	updateLock      sync.Mutex
+	updateStop      chan struct{}
+	data            *PlayerInfo_Storage
+	offlineCache    map[string]*playerInfo_OfflineCache
+	opList          playerInfo_OpList
+	config          PlayerInfo_Config
+	offlineLock     sync.Mutex
+	joinHandler     []func(player string)
+	leaveHandler    []func(player string)
+	dimHandler      []func(player string, from string, to string)
+	posHandler      []func(player string, from *MinecraftPosition, to *MinecraftPosition)
+	afkHandler      []func(player string, afk bool)
+	deathHandler    []func(player string, cause string, killer string)
+	chatHandler     []func(player string, message string)
+	chatFormat      *regexp.Regexp
+	pingFormat      *regexp.Regexp
+	migrations      map[string]map[int]func(old json.RawMessage) (json.RawMessage, error)
+	migrationLock   sync.RWMutex
+	handlerLock     sync.RWMutex
+	session         map[string]time.Time
+	sessionLock     sync.Mutex
+	history         map[string]*playerInfo_PositionRing
+	afk             map[string]*playerInfo_AfkState
+	afkLock         sync.Mutex
+	historyLock     sync.RWMutex
+	commitTimer     *time.Timer
+	dirty           map[string]*MinecraftPlayerInfo
+	commitLock      sync.Mutex
+	extraLock       sync.Mutex
+}
+
+const PlayerInfo_PositionHistorySize = 20
+const PlayerInfo_ResolveWorkers = 4
+
+type TimedPosition struct {
+	Position *MinecraftPosition
+	Time     time.Time
+}
+
+type playerInfo_PositionRing struct {
+	buf  [PlayerInfo_PositionHistorySize]TimedPosition
+	next int
+	size int
+}
+
+func (r *playerInfo_PositionRing) push(p TimedPosition) {
+	r.buf[r.next] = p
+	r.next = (r.next + 1) % len(r.buf)
+	r.size = min(r.size+1, len(r.buf))
+}
+
+func (r *playerInfo_PositionRing) list() []TimedPosition {
+	out := make([]TimedPosition, 0, r.size)
+	for i := 0; i < r.size; i++ {
+		out = append(out, r.buf[(r.next-r.size+i+len(r.buf))%len(r.buf)])
+	}
+	return out
 }
 
 var PlayerEnterLeaveMessage = regexp.MustCompile(`(left|joined) the game`)
+var GamemodeChangeMessage = []*regexp.Regexp{
+	regexp.MustCompile(`\]: \[(\w+): Set own game mode to (\w+) Mode\]`),
+	regexp.MustCompile(`\]: (\w+) set own game mode to (\w+) Mode`),
+	regexp.MustCompile(`\]: (?:\[\w+: )?Set (\w+)'s game mode to (\w+) Mode`),
+}
+
+type PlayerInfo_Config struct {
+	WorldNames  map[string]string // 维度 id -> 显示名称, 用于模组/数据包添加的维度
+	ChatFormat  string            // 自定义聊天格式的正则, 需依次捕获玩家名与消息, 为空时使用原版格式
+	PingCommand string            // 查询玩家延迟的模组命令, 为空时仅解析 list 输出中的延迟
+	PingFormat  string            // PingCommand 输出的正则, 需依次捕获玩家名与延迟 (毫秒)
+}
+
+type PlayerInfo_Extra struct {
+	Deaths           int64
+	Distance         float64 // 同一维度内移动的距离, 不含传送
+	DimensionChanges int64
+}
+
+var OfflineModeMessage = regexp.MustCompile(`SERVER IS RUNNING IN OFFLINE/INSECURE MODE`)
+var OnlineModeProperty = regexp.MustCompile(`(?m)^\s*online-mode\s*=\s*(\w+)`)
+
+func ComputeOfflineUUID(name string) string {
+	hash := md5.Sum([]byte("OfflinePlayer:" + name))
+	hash[6] = hash[6]&0x0f | 0x30
+	hash[8] = hash[8]&0x3f | 0x80
+	hexUUID := hex.EncodeToString(hash[:])
+	return fmt.Sprintf("%s-%s-%s-%s-%s", hexUUID[0:8], hexUUID[8:12], hexUUID[12:16], hexUUID[16:20], hexUUID[20:])
+}
 
 func (pi *PlayerInfo) Init(pm pluginabi.PluginManager) (err error) {
 	err = pi.BasePlugin.Init(pm, pi)
@@ -153,7 +302,46 @@ func (pi *PlayerInfo) Init(pm pluginabi.PluginManager) (err error) {
 		return err
 	}
 	pi.data = &PlayerInfo_Storage{PlayerInfo: map[string]*MinecraftPlayerInfo{}, UUIDMap: map[string]string{}}
+	pi.offlineCache = make(map[string]*playerInfo_OfflineCache)
+	pi.session = make(map[string]time.Time)
+	pi.history = make(map[string]*playerInfo_PositionRing)
+	pi.afk = make(map[string]*playerInfo_AfkState)
+	pi.dirty = make(map[string]*MinecraftPlayerInfo)
+	if pi.Store == nil {
+		pi.Store = &PlayerInfo_JSONStore{Path: pi.DataPath("playerinfo.json")}
+	}
+	if pi.WorldDir == "" {
+		pi.WorldDir = "world"
+	}
+	if pi.RefreshInterval <= 0 {
+		pi.RefreshInterval = 60 * time.Second
+	}
+	if pi.PositionTTL <= 0 {
+		pi.PositionTTL = 5 * time.Second
+	}
+	if pi.AfkTimeout <= 0 {
+		pi.AfkTimeout = PlayerInfo_DefaultAfkTimeout
+	}
+	if pi.MaxTravelStep <= 0 {
+		pi.MaxTravelStep = 1000
+	}
+	if pi.Mojang == nil {
+		pi.Mojang = NewMojangResolver(5*time.Second, 6*time.Hour)
+	}
+	pi.config = PlayerInfo_Config{WorldNames: map[string]string{}}
+	err = pi.LoadConfig(&pi.config)
+	if err != nil {
+		pi.Println(color.RedString("读取玩家信息配置失败: "), color.MagentaString(err.Error()))
+	}
+	worldNames.override(pi.config.WorldNames)
+	pi.chatFormat = pi.compileChatFormat(pi.config.ChatFormat)
+	pi.pingFormat = pi.compilePingFormat(pi.config.PingFormat)
 	pm.RegisterLogProcesser(pi, pi.playerJoinLeaveEvent)
+	pm.RegisterLogProcesser(pi, pi.gamemodeChangeEvent)
+	pm.RegisterLogProcesser(pi, pi.chatEvent)
+	// 死亡消息需逐个匹配大量模板, 只处理 INFO 日志
+	pm.RegisterStructuredLogProcesser(pi, pluginabi.LogFilter{Levels: []string{"INFO"}}, pi.deathEvent)
+	pi.detectServerMode()
 	err = pi.Load()
 	if err != nil {
 		pi.Println(color.RedString("加载存储的玩家数据失败"))
@@ -161,6 +349,88 @@ func (pi *PlayerInfo) Init(pm pluginabi.PluginManager) (err error) {
 	return nil
 }
 
+func (pi *PlayerInfo) detectServerMode() {
+	switch pi.Mode {
+	case PlayerInfo_ModeOnline:
+		pi.offlineMode.Store(false)
+		return
+	case PlayerInfo_ModeOffline:
+		pi.offlineMode.Store(true)
+		return
+	}
+	properties, err := os.ReadFile(filepath.Join(filepath.Dir(pi.WorldDir), "server.properties"))
+	if err == nil {
+		match := OnlineModeProperty.FindSubmatch(properties)
+		if len(match) == 2 && string(match[1]) == "false" {
+			pi.setOfflineMode()
+		}
+	}
+	pi.pm.RegisterLogProcesser(pi, pi.serverModeEvent)
+}
+
+func (pi *PlayerInfo) setOfflineMode() {
+	if !pi.offlineMode.Swap(true) {
+		pi.Println(color.YellowString("服务器运行在离线模式, UUID 将由玩家名计算"))
+	}
+}
+
+func (pi *PlayerInfo) serverModeEvent(log string, _ bool) {
+	if OfflineModeMessage.MatchString(log) {
+		pi.setOfflineMode()
+	}
+}
+
+func (pi *PlayerInfo) gamemodeChangeEvent(log string, _ bool) {
+	for _, gamemodeRegex := range GamemodeChangeMessage {
+		match := gamemodeRegex.FindStringSubmatch(log)
+		if len(match) != 3 {
+			continue
+		}
+		playerInfo := pi.getCachedPlayerInfo(match[1])
+		playerInfo.lock.Lock()
+		playerInfo.Gamemode = strings.ToLower(match[2])
+		playerInfo.lock.Unlock()
+		pi.Commit(playerInfo)
+		return
+	}
+}
+
+func (pi *PlayerInfo) deathEvent(line pluginabi.LogLine) {
+	player, cause, killer, _, ok := ParseDeathMessage(line.Raw)
+	if !ok || !slices.Contains(pi.GetPlayerList(), player) {
+		return
+	}
+	playerInfo := pi.getCachedPlayerInfo(player)
+	pi.updateExtra(playerInfo, func(extra *PlayerInfo_Extra) {
+		extra.Deaths++
+	})
+	pi.Commit(playerInfo)
+	pi.handlerLock.RLock()
+	for _, handler := range pi.deathHandler {
+		go handler(player, cause, killer)
+	}
+	pi.handlerLock.RUnlock()
+	pi.Publish(EventPlayerDeath, PlayerDeathEvent{Player: player, Cause: cause, Killer: killer})
+}
+
+func (pi *PlayerInfo) RegisterDeathHandler(cb func(player string, cause string, killer string)) {
+	pi.handlerLock.Lock()
+	defer pi.handlerLock.Unlock()
+	pi.deathHandler = append(pi.deathHandler, cb)
+}
+
+func (pi *PlayerInfo) GetDeaths(player string) int64 {
+	pi.data.playerInfoLock.RLock()
+	playerInfo, ok := pi.data.PlayerInfo[player]
+	pi.data.playerInfoLock.RUnlock()
+	if !ok {
+		return 0
+	}
+	extra := &PlayerInfo_Extra{}
+	playerInfo.GetExtra(pi, extra)
+	return extra.Deaths
+}
+
 func (pi *PlayerInfo) playerJoinLeaveEvent(log string, _ bool) {
 	if PlayerEnterLeaveMessage.MatchString(log) {
 		pi.updatePlayerList()
@@ -185,12 +455,17 @@ func (pi *PlayerInfo) getPlayerName(uuid string) (player string, err error) {
 		return player, nil
 	}
 	playerEntitydata := pi.RunCommand(fmt.Sprintf("data get entity %s", uuid))
-	if !strings.Contains(playerEntitydata, "entity data") {
-		return "", fmt.Errorf("not found")
+	if strings.Contains(playerEntitydata, "entity data") {
+		player, _, ok = strings.Cut(playerEntitydata, " ")
 	}
-	player, _, ok = strings.Cut(playerEntitydata, " ")
 	if !ok || player == uuid {
-		return "", fmt.Errorf("not found")
+		if pi.offlineMode.Load() {
+			return "", fmt.Errorf("not found")
+		}
+		player, err = pi.Mojang.GetName(uuid)
+		if err != nil {
+			return "", fmt.Errorf("not found: %w", err)
+		}
 	}
 	pi.data.uuidMapLock.Lock()
 	pi.data.UUIDMap[uuid] = player
@@ -199,6 +474,13 @@ func (pi *PlayerInfo) getPlayerName(uuid string) (player string, err error) {
 }
 
 func (pi *PlayerInfo) getPlayerUUID(player string) (uuid string, err error) {
+	if pi.offlineMode.Load() {
+		uuid = ComputeOfflineUUID(player)
+		pi.data.uuidMapLock.Lock()
+		pi.data.UUIDMap[uuid] = player
+		pi.data.uuidMapLock.Unlock()
+		return uuid, nil
+	}
 	uuidEntityData := pi.RunCommand("data get entity " + player + " UUID")
 	uuidData := strings.SplitN(uuidEntityData, ":", 2)
 	if len(uuidData) != 2 {
@@ -220,14 +502,10 @@ func (pi *PlayerInfo) getPlayerUUID(player string) (uuid string, err error) {
 	if err != nil {
 		return "", err
 	}
-	pi.data.uuidMapLock.RLock()
-	_, ok := pi.data.UUIDMap[uuid]
-	pi.data.uuidMapLock.RUnlock()
-	if !ok {
-		pi.data.uuidMapLock.Lock()
-		pi.data.UUIDMap[uuid] = player
-		pi.data.uuidMapLock.Unlock()
-	}
+	// 玩家可能已改名, 始终记录最新的用户名
+	pi.data.uuidMapLock.Lock()
+	pi.data.UUIDMap[uuid] = player
+	pi.data.uuidMapLock.Unlock()
 	return uuid, err
 }
 
@@ -255,19 +533,286 @@ func (pi *PlayerInfo) getPlayerPosition(player string) (position *MinecraftPosit
 		return nil, fmt.Errorf("获取 NBT 失败")
 	}
 	position.Dimension = strings.Trim(entityDim[1], `" `)
+	pi.recordPosition(player, position)
 	return position, err
 }
 
+func (pi *PlayerInfo) getPlayerNumber(player string, path string) (float64, error) {
+	entityDataRes := pi.RunCommand("data get entity " + player + " " + path)
+	entityData := strings.SplitN(entityDataRes, ":", 2)
+	if len(entityData) != 2 {
+		return 0, fmt.Errorf("获取 NBT 失败")
+	}
+	// NBT 数值带有类型后缀, 如 20.0f 5b
+	return strconv.ParseFloat(strings.TrimRight(strings.TrimSpace(entityData[1]), "bsLfdBSFD"), 64)
+}
+
+func (pi *PlayerInfo) getPlayerStatus(player string) (health float64, foodLevel int, xpLevel int, err error) {
+	health, err = pi.getPlayerNumber(player, "Health")
+	if err != nil {
+		return
+	}
+	food, err := pi.getPlayerNumber(player, "foodLevel")
+	if err != nil {
+		return
+	}
+	xp, err := pi.getPlayerNumber(player, "XpLevel")
+	if err != nil {
+		return
+	}
+	return health, int(food), int(xp), nil
+}
+
+var ResourceLocation = regexp.MustCompile(`^(?:[a-z0-9_.-]+:)?[a-z0-9_./-]+$`)
+
+func (pi *PlayerInfo) Teleport(player string, pos *MinecraftPosition) error {
+	if pos == nil {
+		return fmt.Errorf("无目标位置")
+	}
+	// 只接受玩家名, 避免拼接出选择器或其他命令参数
+	if !PlayerNamePattern.MatchString(player) {
+		return fmt.Errorf("无效的玩家名: %q", player)
+	}
+	for _, coord := range pos.Position {
+		if math.IsNaN(coord) || math.IsInf(coord, 0) {
+			return fmt.Errorf("非法坐标")
+		}
+	}
+	if !ResourceLocation.MatchString(pos.Dimension) {
+		return fmt.Errorf("非法维度: %s", pos.Dimension)
+	}
+	res := pi.RunCommand(fmt.Sprintf("execute in %s run tp %s %f %f %f", pos.Dimension, player, pos.Position[0], pos.Position[1], pos.Position[2]))
+	if !strings.Contains(res, "Teleported") {
+		reason, _, _ := strings.Cut(res, "\n")
+		if reason == "" {
+			reason = "无响应"
+		}
+		return fmt.Errorf("传送失败: %s", reason)
+	}
+	location := *pos
+	playerInfo := pi.getCachedPlayerInfo(player)
+	playerInfo.lock.Lock()
+	playerInfo.LastLocation = playerInfo.Location
+	playerInfo.Location = &location
+	playerInfo.LocationFetchedAt = time.Now()
+	playerInfo.lock.Unlock()
+	pi.recordPosition(player, &location)
+	return pi.Commit(playerInfo)
+}
+
+func (pi *PlayerInfo) recordPosition(player string, position *MinecraftPosition) {
+	pi.historyLock.Lock()
+	ring, ok := pi.history[player]
+	if !ok {
+		ring = &playerInfo_PositionRing{}
+		pi.history[player] = ring
+	}
+	var last *MinecraftPosition
+	var previous TimedPosition
+	current := TimedPosition{Position: position, Time: time.Now()}
+	if ring.size > 0 {
+		previous = ring.list()[ring.size-1]
+		last = previous.Position
+	}
+	ring.push(current)
+	pi.historyLock.Unlock()
+	if last != nil {
+		pi.trackDistance(player, previous, current)
+	}
+	pi.handlerLock.RLock()
+	defer pi.handlerLock.RUnlock()
+	if last != nil && last.Dimension != position.Dimension {
+		for _, handler := range pi.dimHandler {
+			go handler(player, last.Dimension, position.Dimension)
+		}
+	}
+	// 按位置更新顺序同步调用, 回调不应阻塞
+	for _, handler := range pi.posHandler {
+		handler(player, last, position)
+	}
+}
+
+func (pi *PlayerInfo) trackDistance(player string, from TimedPosition, to TimedPosition) {
+	distance, dimensionChanges := 0.0, int64(0)
+	if from.Position.Dimension != to.Position.Dimension {
+		dimensionChanges = 1
+	} else {
+		for i := range 3 {
+			distance += (to.Position.Position[i] - from.Position.Position[i]) * (to.Position.Position[i] - from.Position.Position[i])
+		}
+		distance = math.Sqrt(distance)
+		// 传送/死亡重生等瞬间位移不计入
+		if distance == 0 || distance > pi.MaxTravelStep {
+			return
+		}
+	}
+	playerInfo := pi.getCachedPlayerInfo(player)
+	pi.updateExtra(playerInfo, func(extra *PlayerInfo_Extra) {
+		extra.Distance += distance
+		extra.DimensionChanges += dimensionChanges
+	})
+	pi.Commit(playerInfo)
+}
+
+// 读取并修改本插件的 Extra, 串行执行避免并发更新相互覆盖
+func (pi *PlayerInfo) updateExtra(playerInfo *MinecraftPlayerInfo, update func(extra *PlayerInfo_Extra)) {
+	pi.extraLock.Lock()
+	defer pi.extraLock.Unlock()
+	extra := &PlayerInfo_Extra{}
+	playerInfo.GetExtra(pi, extra)
+	update(extra)
+	playerInfo.PutExtra(pi, extra)
+}
+
+func (pi *PlayerInfo) GetDistanceTraveled(player string) float64 {
+	pi.data.playerInfoLock.RLock()
+	playerInfo, ok := pi.data.PlayerInfo[player]
+	pi.data.playerInfoLock.RUnlock()
+	if !ok {
+		return 0
+	}
+	extra := &PlayerInfo_Extra{}
+	playerInfo.GetExtra(pi, extra)
+	return extra.Distance
+}
+
+func (pi *PlayerInfo) RegisterDimensionChangeHandler(cb func(player string, from string, to string)) {
+	pi.handlerLock.Lock()
+	defer pi.handlerLock.Unlock()
+	pi.dimHandler = append(pi.dimHandler, cb)
+}
+
+// from 为上一次记录的位置, 首次记录时为 nil
+func (pi *PlayerInfo) RegisterPositionHandler(cb func(player string, from *MinecraftPosition, to *MinecraftPosition)) {
+	pi.handlerLock.Lock()
+	defer pi.handlerLock.Unlock()
+	pi.posHandler = append(pi.posHandler, cb)
+}
+
+func (pi *PlayerInfo) GetPositionHistory(player string) []TimedPosition {
+	pi.historyLock.RLock()
+	defer pi.historyLock.RUnlock()
+	ring, ok := pi.history[player]
+	if !ok {
+		return nil
+	}
+	return ring.list()
+}
+
 func (pi *PlayerInfo) GetPlayerInfo_Position(player string) (playerInfo *MinecraftPlayerInfo, err error) {
 	playerInfo, err = pi.GetPlayerInfo(player)
 	if err != nil {
 		return nil, err
 	}
-	playerInfo.Location, err = pi.getPlayerPosition(player)
+	// GetPlayerInfo 只刷新在线玩家的位置, 此处对离线玩家仍直接查询以返回错误
+	playerInfo.lock.RLock()
+	stale := time.Since(playerInfo.LocationFetchedAt) > pi.PositionTTL
+	playerInfo.lock.RUnlock()
+	if stale {
+		err = pi.fetchLocation(playerInfo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return playerInfo, nil
+}
+
+// 不可持有 playerInfo.lock 调用
+func (pi *PlayerInfo) fetchLocation(playerInfo *MinecraftPlayerInfo) error {
+	playerInfo.lock.RLock()
+	player := playerInfo.Player
+	playerInfo.lock.RUnlock()
+	position, err := pi.getPlayerPosition(player)
+	if err != nil {
+		return err
+	}
+	playerInfo.lock.Lock()
+	playerInfo.Location = position
+	playerInfo.LocationFetchedAt = time.Now()
+	playerInfo.lock.Unlock()
+	return nil
+}
+
+func (pi *PlayerInfo) GetPlayerInfo_Full(player string) (playerInfo *MinecraftPlayerInfo, err error) {
+	playerInfo, err = pi.GetPlayerInfo_Position(player)
+	if err != nil {
+		return nil, err
+	}
+	health, foodLevel, xpLevel, err := pi.getPlayerStatus(player)
 	if err != nil {
 		return nil, err
 	}
-	return playerInfo, err
+	playerInfo.lock.Lock()
+	playerInfo.Health = health
+	playerInfo.FoodLevel = foodLevel
+	playerInfo.XpLevel = xpLevel
+	playerInfo.lock.Unlock()
+	return playerInfo, nil
+}
+
+func (pi *PlayerInfo) GetPlayerInfoByUUID(uuid string) (*MinecraftPlayerInfo, error) {
+	uuid = strings.ToLower(uuid)
+	pi.data.uuidMapLock.RLock()
+	player, ok := pi.data.UUIDMap[uuid]
+	pi.data.uuidMapLock.RUnlock()
+	if !ok {
+		stored, err := pi.Store.LookupUUID(uuid)
+		player, ok = stored, err == nil && stored != ""
+	}
+	if !ok {
+		var err error
+		player, err = pi.getPlayerName(uuid)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pi.GetPlayerInfo(player)
+}
+
+// 只查询已缓存的玩家, 不会创建条目或执行命令
+func (pi *PlayerInfo) LookupPlayerInfo(player string) (*MinecraftPlayerInfo, bool) {
+	if len(player) == 36 {
+		pi.data.uuidMapLock.RLock()
+		name, ok := pi.data.UUIDMap[strings.ToLower(player)]
+		pi.data.uuidMapLock.RUnlock()
+		if ok {
+			player = name
+		}
+	}
+	pi.data.playerInfoLock.RLock()
+	playerInfo, ok := pi.data.PlayerInfo[player]
+	pi.data.playerInfoLock.RUnlock()
+	if !ok {
+		return pi.loadStored(player)
+	}
+	return playerInfo, ok
+}
+
+// 缓存未命中时从存储中载入
+func (pi *PlayerInfo) loadStored(player string) (*MinecraftPlayerInfo, bool) {
+	stored, err := pi.Store.Lookup(player)
+	if err != nil {
+		pi.Println(color.RedString("查询玩家数据失败: "), color.MagentaString(err.Error()))
+		return nil, false
+	}
+	if stored == nil {
+		return nil, false
+	}
+	if stored.Extra == nil {
+		stored.Extra = make(MinecraftPlayerInfo_Extra)
+	}
+	stored.Player = player
+	stored.playerInfo = pi
+	pi.data.Lock()
+	defer pi.data.Unlock()
+	if current, ok := pi.data.PlayerInfo[player]; ok {
+		return current, true
+	}
+	pi.data.PlayerInfo[player] = stored
+	if stored.UUID != "" {
+		pi.data.UUIDMap[strings.ToLower(stored.UUID)] = player
+	}
+	return stored, true
 }
 
 func (pi *PlayerInfo) GetPlayerInfo(player string) (playerInfo *MinecraftPlayerInfo, err error) {
@@ -284,31 +829,73 @@ func (pi *PlayerInfo) GetPlayerInfo(player string) (playerInfo *MinecraftPlayerI
 	playerInfo, ok = pi.data.PlayerInfo[player]
 	pi.data.playerInfoLock.RUnlock()
 	if !ok {
+		playerInfo, ok = pi.loadStored(player)
+	}
+	if !ok {
+		// 新条目在 UUID 查询成功后才加入缓存, 查询失败时不留下空 UUID 的记录
 		playerInfo = &MinecraftPlayerInfo{Player: player, playerInfo: pi, Extra: make(map[string]any), UUID: uuid}
+	}
+	// 查询 UUID 需执行命令或请求 Mojang API, 不可持有 playerInfo.lock, 查询完成后再加锁写入
+	online := slices.Contains(pi.GetPlayerList(), player)
+	playerInfo.lock.RLock()
+	known := playerInfo.UUID
+	playerInfo.lock.RUnlock()
+	if known == "" {
+		if uuid == "" {
+			if online || pi.offlineMode.Load() {
+				uuid, err = pi.getPlayerUUID(player)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				// 玩家不在线时通过 Mojang API 查询
+				uuid, err = pi.Mojang.GetUUID(player)
+				if err != nil {
+					return nil, err
+				}
+				pi.data.uuidMapLock.Lock()
+				pi.data.UUIDMap[uuid] = player
+				pi.data.uuidMapLock.Unlock()
+			}
+		}
+	}
+	if !ok {
 		pi.data.playerInfoLock.Lock()
-		pi.data.PlayerInfo[player] = playerInfo
+		// 查询期间可能已由其他调用创建, 以已有条目为准
+		if current, exist := pi.data.PlayerInfo[player]; exist {
+			playerInfo = current
+		} else {
+			pi.data.PlayerInfo[player] = playerInfo
+			defer pi.Commit(playerInfo)
+		}
 		pi.data.playerInfoLock.Unlock()
-		defer pi.Commit(playerInfo)
-	} else {
+	}
+	if known == "" || !ok {
 		playerInfo.lock.Lock()
-		defer playerInfo.lock.Unlock()
-		if playerInfo.Extra == nil {
-			playerInfo.Extra = make(map[string]any)
+		if playerInfo.UUID == "" {
+			playerInfo.UUID = uuid
 		}
-		playerInfo.playerInfo = pi
+		known = playerInfo.UUID
+		playerInfo.lock.Unlock()
 	}
-	if playerInfo.UUID == "" {
-		if uuid == "" {
-			playerInfo.UUID, err = pi.getPlayerUUID(player)
-			if err != nil {
+	playerInfo.lock.RLock()
+	stale := time.Since(playerInfo.LocationFetchedAt) > pi.PositionTTL
+	located := playerInfo.Location != nil
+	playerInfo.lock.RUnlock()
+	if online {
+		// 获取位置会触发位置回调, 需在释放 playerInfo.lock 后进行
+		if stale {
+			if err = pi.fetchLocation(playerInfo); err != nil {
 				return nil, err
 			}
 		}
-	}
-	if playerInfo.Location == nil {
-		playerInfo.Location, err = pi.getPlayerPosition(player)
-		if err != nil {
-			return nil, err
+	} else if !located {
+		if offlineInfo, err := pi.GetOfflinePlayerInfo(known); err == nil {
+			playerInfo.lock.Lock()
+			if playerInfo.Location == nil {
+				playerInfo.Location = offlineInfo.Location
+			}
+			playerInfo.lock.Unlock()
 		}
 	}
 	return playerInfo, nil
@@ -321,24 +908,315 @@ func (pi *PlayerInfo) GetPlayerList() []string {
 }
 
 func (pi *PlayerInfo) updatePlayerList() {
+	// 定时刷新与加入/离开事件可能同时触发, 串行执行避免旧结果覆盖新结果
+	pi.updateLock.Lock()
+	defer pi.updateLock.Unlock()
 	playerlistMsg := pi.RunCommand("list")
 	playerlistSplitText := strings.SplitN(playerlistMsg, ":", 2)
 	if len(playerlistSplitText) == 2 {
 		playerList := strings.Split(strings.TrimSpace(playerlistSplitText[1]), ",")
+		pings := make(map[string]int)
 		pi.playerListLock.Lock()
+		lastPlayerList := pi.playerList
 		pi.playerList = lo.FilterMap(playerList, func(players string, index int) (string, bool) {
-			player := strings.TrimSpace(players)
+			player, ping, ok := pi.parsePlayerListEntry(players)
+			if ok {
+				pings[player] = ping
+			}
 			return player, player != ""
 		})
+		leftPlayers, joinedPlayers := lo.Difference(lastPlayerList, pi.playerList)
+		if !pi.playerListReady {
+			// 启动时已在线的玩家不视为加入
+			leftPlayers, joinedPlayers = nil, nil
+			pi.playerListReady = true
+		}
+		currentPlayers := slices.Clone(pi.playerList)
 		pi.playerListLock.Unlock()
+		pi.trackPlaytime(leftPlayers, currentPlayers)
+		pi.clearAfk(leftPlayers...)
+		pi.updatePing(currentPlayers, leftPlayers, pings)
+		pi.handlerLock.RLock()
+		for _, player := range joinedPlayers {
+			for _, handler := range pi.joinHandler {
+				go handler(player)
+			}
+		}
+		for _, player := range leftPlayers {
+			for _, handler := range pi.leaveHandler {
+				go handler(player)
+			}
+		}
+		pi.handlerLock.RUnlock()
+		for _, player := range joinedPlayers {
+			pi.Publish(EventPlayerJoin, player)
+		}
+		for _, player := range leftPlayers {
+			pi.Publish(EventPlayerLeave, player)
+		}
+	}
+}
+
+func (pi *PlayerInfo) getCachedPlayerInfo(player string) *MinecraftPlayerInfo {
+	if playerInfo, ok := pi.LookupPlayerInfo(player); ok {
+		return playerInfo
+	}
+	pi.data.playerInfoLock.Lock()
+	defer pi.data.playerInfoLock.Unlock()
+	playerInfo, ok := pi.data.PlayerInfo[player]
+	if !ok {
+		playerInfo = &MinecraftPlayerInfo{Player: player, playerInfo: pi, Extra: make(map[string]any)}
+		pi.data.PlayerInfo[player] = playerInfo
+	}
+	return playerInfo
+}
+
+// 需持有 pi.sessionLock
+func (pi *PlayerInfo) endSession(player string, now time.Time) {
+	start, ok := pi.session[player]
+	delete(pi.session, player)
+	playerInfo := pi.getCachedPlayerInfo(player)
+	playerInfo.lock.Lock()
+	if ok {
+		playerInfo.PlaytimeSeconds += int64(now.Sub(start).Seconds())
+	}
+	playerInfo.LastSeen = now.Truncate(time.Second)
+	playerInfo.lock.Unlock()
+	pi.Commit(playerInfo)
+}
+
+func (pi *PlayerInfo) trackPlaytime(leftPlayers []string, currentPlayers []string) {
+	now := time.Now()
+	pi.sessionLock.Lock()
+	defer pi.sessionLock.Unlock()
+	for _, player := range leftPlayers {
+		pi.endSession(player, now)
+	}
+	pi.historyLock.Lock()
+	for _, player := range leftPlayers {
+		delete(pi.history, player)
+	}
+	pi.historyLock.Unlock()
+	// 加入时间未知的在线玩家 (如守护进程重启) 从现在开始计时
+	for _, player := range currentPlayers {
+		if _, ok := pi.session[player]; !ok {
+			pi.session[player] = now
+		}
+	}
+}
+
+func (pi *PlayerInfo) GetPlaytime(player string) time.Duration {
+	pi.data.playerInfoLock.RLock()
+	playerInfo, ok := pi.data.PlayerInfo[player]
+	pi.data.playerInfoLock.RUnlock()
+	playtime := time.Duration(0)
+	if ok {
+		playerInfo.lock.RLock()
+		playtime = time.Duration(playerInfo.PlaytimeSeconds) * time.Second
+		playerInfo.lock.RUnlock()
+	}
+	pi.sessionLock.Lock()
+	if start, ok := pi.session[player]; ok {
+		playtime += time.Since(start)
+	}
+	pi.sessionLock.Unlock()
+	return playtime
+}
+
+func (pi *PlayerInfo) GetLastSeen(player string) (time.Time, bool) {
+	pi.data.playerInfoLock.RLock()
+	playerInfo, ok := pi.data.PlayerInfo[player]
+	pi.data.playerInfoLock.RUnlock()
+	if !ok {
+		return time.Time{}, false
+	}
+	playerInfo.lock.RLock()
+	defer playerInfo.lock.RUnlock()
+	return playerInfo.LastSeen, !playerInfo.LastSeen.IsZero()
+}
+
+func (pi *PlayerInfo) SetDisplayName(player string, nick string) error {
+	account, ok := pi.ResolveName(player)
+	if !ok {
+		return fmt.Errorf("玩家 %s 不存在", player)
+	}
+	playerInfo := pi.getCachedPlayerInfo(account)
+	playerInfo.lock.Lock()
+	playerInfo.DisplayName = nick
+	playerInfo.lock.Unlock()
+	return pi.Commit(playerInfo)
+}
+
+func (pi *PlayerInfo) GetDisplayName(player string) string {
+	pi.data.playerInfoLock.RLock()
+	playerInfo, ok := pi.data.PlayerInfo[player]
+	pi.data.playerInfoLock.RUnlock()
+	if !ok {
+		return player
+	}
+	playerInfo.lock.RLock()
+	defer playerInfo.lock.RUnlock()
+	if playerInfo.DisplayName == "" {
+		return player
+	}
+	return playerInfo.DisplayName
+}
+
+// 将账户名或昵称解析为账户名, 在线玩家优先
+func (pi *PlayerInfo) ResolveName(input string) (account string, ok bool) {
+	onlinePlayers := pi.GetPlayerList()
+	if slices.Contains(onlinePlayers, input) {
+		return input, true
+	}
+	pi.data.playerInfoLock.RLock()
+	defer pi.data.playerInfoLock.RUnlock()
+	if _, ok := pi.data.PlayerInfo[input]; ok {
+		return input, true
+	}
+	for player, playerInfo := range pi.data.PlayerInfo {
+		playerInfo.lock.RLock()
+		nick := playerInfo.DisplayName
+		playerInfo.lock.RUnlock()
+		if nick == "" || nick != input {
+			continue
+		}
+		account = player
+		if slices.Contains(onlinePlayers, player) {
+			return account, true
+		}
+	}
+	return account, account != ""
+}
+
+func (pi *PlayerInfo) RegisterJoinHandler(cb func(player string)) {
+	pi.handlerLock.Lock()
+	defer pi.handlerLock.Unlock()
+	pi.joinHandler = append(pi.joinHandler, cb)
+}
+
+func (pi *PlayerInfo) RegisterLeaveHandler(cb func(player string)) {
+	pi.handlerLock.Lock()
+	defer pi.handlerLock.Unlock()
+	pi.leaveHandler = append(pi.leaveHandler, cb)
+}
+
+func (pi *PlayerInfo) ResolveAllUUIDs() {
+	players := lo.Filter(pi.GetPlayerList(), func(player string, _ int) bool {
+		playerInfo := pi.getCachedPlayerInfo(player)
+		playerInfo.lock.RLock()
+		defer playerInfo.lock.RUnlock()
+		return playerInfo.UUID == ""
+	})
+	if len(players) == 0 {
+		return
+	}
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < min(PlayerInfo_ResolveWorkers, len(players)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for player := range queue {
+				uuid, err := pi.getPlayerUUID(player)
+				if err != nil {
+					pi.Println(color.RedString("获取玩家 "), color.BlueString(player), color.RedString(" 的 UUID 失败: "), color.MagentaString(err.Error()))
+					continue
+				}
+				playerInfo := pi.getCachedPlayerInfo(player)
+				playerInfo.lock.Lock()
+				playerInfo.UUID = uuid
+				playerInfo.lock.Unlock()
+				pi.Commit(playerInfo)
+			}
+		}()
+	}
+	for _, player := range players {
+		queue <- player
+	}
+	close(queue)
+	wg.Wait()
+}
+
+func (pi *PlayerInfo) refreshPositions() {
+	for _, player := range pi.GetPlayerList() {
+		position, err := pi.getPlayerPosition(player)
+		if err != nil {
+			continue
+		}
+		pi.trackAfk(player, position)
+		playerInfo := pi.getCachedPlayerInfo(player)
+		playerInfo.lock.Lock()
+		playerInfo.Location = position
+		playerInfo.LocationFetchedAt = time.Now()
+		playerInfo.lock.Unlock()
+		pi.Commit(playerInfo)
+	}
+}
+
+func (pi *PlayerInfo) updateWorker(stop chan struct{}) {
+	updateTicker := time.NewTicker(pi.RefreshInterval)
+	defer updateTicker.Stop()
+	for {
+		select {
+		case <-updateTicker.C:
+			pi.updatePlayerList()
+			pi.refreshPositions()
+		case <-stop:
+			return
+		}
 	}
 }
 
 func (pi *PlayerInfo) Start() {
 	pi.updatePlayerList()
+	go pi.ResolveAllUUIDs()
+	pi.stopUpdate()
+	pi.updateStop = make(chan struct{})
+	go pi.updateWorker(pi.updateStop)
+}
+
+func (pi *PlayerInfo) stopUpdate() {
+	if pi.updateStop != nil {
+		close(pi.updateStop)
+		pi.updateStop = nil
+	}
 }
 
 func (pi *PlayerInfo) Pause() {
+	pi.stopUpdate()
+	pi.playerListLock.Lock()
+	pi.playerListReady = false
+	pi.playerListLock.Unlock()
+	pi.afkLock.Lock()
+	pi.afk = make(map[string]*playerInfo_AfkState)
+	pi.afkLock.Unlock()
+	now := time.Now()
+	pi.sessionLock.Lock()
+	for player := range pi.session {
+		pi.endSession(player, now)
+	}
+	pi.sessionLock.Unlock()
+	pi.flushCommit()
+}
+
+func (pi *PlayerInfo) Shutdown() {
+	pi.data.playerInfoLock.RLock()
+	all := maps.Values(pi.data.PlayerInfo)
+	pi.data.playerInfoLock.RUnlock()
+	pi.commitLock.Lock()
+	if pi.commitTimer != nil {
+		pi.commitTimer.Stop()
+		pi.commitTimer = nil
+	}
+	// 退出时保存全部缓存条目, 防止遗漏未调用 Commit 的修改
+	for _, playerInfo := range all {
+		pi.dirty[playerInfo.Player] = playerInfo
+	}
+	pi.commitLock.Unlock()
+	err := pi.CommitNow()
+	if err != nil {
+		pi.Println(color.RedString("保存玩家数据失败: "), color.MagentaString(err.Error()))
+	}
 }
 
 func (pi *PlayerInfo) Name() string {
@@ -350,29 +1228,113 @@ func (pi *PlayerInfo) DisplayName() string {
 }
 
 func (pi *PlayerInfo) Load() error {
-	data, err := os.ReadFile("data/playerinfo.json")
+	loaded, err := pi.Store.Load()
 	if err != nil {
 		return err
 	}
+	if js, ok := pi.Store.(*PlayerInfo_JSONStore); ok {
+		if loadedFrom := js.loadedFrom(); loadedFrom != js.Path {
+			pi.Println(color.RedString("玩家数据文件损坏, 已从备份加载: "), color.YellowString(loadedFrom))
+		} else {
+			pi.Println(color.YellowString("已加载玩家数据: "), color.GreenString(loadedFrom))
+		}
+	}
 	pi.data.Lock()
 	defer pi.data.Unlock()
-	err = json.Unmarshal(data, &pi.data)
-	if err != nil {
-		return err
+	for player, playerInfo := range loaded.PlayerInfo {
+		if playerInfo == nil {
+			continue
+		}
+		if playerInfo.Extra == nil {
+			playerInfo.Extra = make(MinecraftPlayerInfo_Extra)
+		}
+		// 已有的条目可能正被其他插件持有, 原地更新以保持指针有效
+		if current, ok := pi.data.PlayerInfo[player]; ok {
+			current.replace(playerInfo)
+			continue
+		}
+		playerInfo.playerInfo = pi
+		pi.data.PlayerInfo[player] = playerInfo
+	}
+	for uuid, player := range loaded.UUIDMap {
+		pi.data.UUIDMap[uuid] = player
 	}
+	pi.rebuildUUIDIndex()
 	return nil
 }
 
+// 需持有 pi.data 的写锁
+func (pi *PlayerInfo) rebuildUUIDIndex() {
+	lastSeen := make(map[string]time.Time)
+	for player, playerInfo := range pi.data.PlayerInfo {
+		playerInfo.lock.RLock()
+		uuid, seen := playerInfo.UUID, playerInfo.LastSeen
+		playerInfo.lock.RUnlock()
+		if uuid == "" {
+			continue
+		}
+		if last, ok := lastSeen[uuid]; ok && !seen.After(last) {
+			continue
+		}
+		lastSeen[uuid] = seen
+		pi.data.UUIDMap[uuid] = player
+	}
+}
+
 func (pi *PlayerInfo) Commit(mpi *MinecraftPlayerInfo) error {
 	if mpi == nil {
 		return fmt.Errorf("无玩家信息")
 	}
-	pi.data.RLock()
-	saveData, err := json.MarshalIndent(pi.data, "", "\t")
-	pi.data.RUnlock()
+	pi.commitLock.Lock()
+	pi.dirty[mpi.Player] = mpi
+	pi.commitLock.Unlock()
+	pi.requestCommit()
+	return nil
+}
+
+func (pi *PlayerInfo) requestCommit() {
+	pi.commitLock.Lock()
+	defer pi.commitLock.Unlock()
+	if pi.commitTimer != nil {
+		pi.commitTimer.Stop()
+	}
+	pi.commitTimer = time.AfterFunc(2*time.Second, func() {
+		err := pi.CommitNow()
+		if err != nil {
+			pi.Println(color.RedString("保存玩家数据失败: "), color.MagentaString(err.Error()))
+		}
+	})
+}
+
+func (pi *PlayerInfo) flushCommit() {
+	pi.commitLock.Lock()
+	pending := pi.commitTimer != nil && pi.commitTimer.Stop()
+	pi.commitTimer = nil
+	pi.commitLock.Unlock()
+	if !pending {
+		return
+	}
+	err := pi.CommitNow()
 	if err != nil {
-		return err
+		pi.Println(color.RedString("保存玩家数据失败: "), color.MagentaString(err.Error()))
 	}
+}
 
-	return os.WriteFile("data/playerinfo.json", saveData, 0644)
+func (pi *PlayerInfo) CommitNow() error {
+	pi.commitLock.Lock()
+	dirty := maps.Values(pi.dirty)
+	pi.dirty = make(map[string]*MinecraftPlayerInfo)
+	pi.commitLock.Unlock()
+	err := pi.Store.Save(pi.data, dirty)
+	if err != nil {
+		// 保存失败时放回, 等待下次提交
+		pi.commitLock.Lock()
+		for _, playerInfo := range dirty {
+			if _, ok := pi.dirty[playerInfo.Player]; !ok {
+				pi.dirty[playerInfo.Player] = playerInfo
+			}
+		}
+		pi.commitLock.Unlock()
+	}
+	return err
 }
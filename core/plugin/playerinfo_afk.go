@@ -0,0 +1,106 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import "time"
+
+const PlayerInfo_DefaultAfkTimeout = 5 * time.Minute
+
+type playerInfo_AfkState struct {
+	position *MinecraftPosition
+	still    int
+	afk      bool
+}
+
+// 位置按 RefreshInterval 刷新, 需要连续多少次刷新位置不变才视为挂机
+func (pi *PlayerInfo) afkTicks() int {
+	return max(int((pi.AfkTimeout+pi.RefreshInterval-1)/pi.RefreshInterval), 1)
+}
+
+func (pi *PlayerInfo) fireAfk(player string, afk bool) {
+	pi.handlerLock.RLock()
+	defer pi.handlerLock.RUnlock()
+	for _, handler := range pi.afkHandler {
+		go handler(player, afk)
+	}
+}
+
+// 由定时刷新调用, 按需获取的位置不参与判断
+func (pi *PlayerInfo) trackAfk(player string, position *MinecraftPosition) {
+	pi.afkLock.Lock()
+	state, ok := pi.afk[player]
+	if !ok {
+		state = &playerInfo_AfkState{}
+		pi.afk[player] = state
+	}
+	if state.position == nil || *state.position != *position {
+		state.position = position
+		state.still = 0
+		wasAfk := state.afk
+		state.afk = false
+		pi.afkLock.Unlock()
+		if wasAfk {
+			pi.fireAfk(player, false)
+		}
+		return
+	}
+	state.still++
+	becomeAfk := !state.afk && state.still >= pi.afkTicks()
+	if becomeAfk {
+		state.afk = true
+	}
+	pi.afkLock.Unlock()
+	if becomeAfk {
+		pi.fireAfk(player, true)
+	}
+}
+
+// 聊天/命令等活动
+func (pi *PlayerInfo) markActive(player string) {
+	pi.afkLock.Lock()
+	state, ok := pi.afk[player]
+	if !ok {
+		pi.afkLock.Unlock()
+		return
+	}
+	state.still = 0
+	wasAfk := state.afk
+	state.afk = false
+	pi.afkLock.Unlock()
+	if wasAfk {
+		pi.fireAfk(player, false)
+	}
+}
+
+func (pi *PlayerInfo) clearAfk(players ...string) {
+	pi.afkLock.Lock()
+	defer pi.afkLock.Unlock()
+	for _, player := range players {
+		delete(pi.afk, player)
+	}
+}
+
+func (pi *PlayerInfo) IsAfk(player string) bool {
+	pi.afkLock.Lock()
+	defer pi.afkLock.Unlock()
+	state, ok := pi.afk[player]
+	return ok && state.afk
+}
+
+func (pi *PlayerInfo) RegisterAfkHandler(cb func(player string, afk bool)) {
+	pi.handlerLock.Lock()
+	defer pi.handlerLock.Unlock()
+	pi.afkHandler = append(pi.afkHandler, cb)
+}
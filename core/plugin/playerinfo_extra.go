@@ -0,0 +1,149 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+)
+
+// 返回缓存的值, 原始 JSON 时迁移后解码到 newValue() 并缓存
+func (mpi *MinecraftPlayerInfo) loadExtra(namespace string, newValue func() any) (any, bool, error) {
+	mpi.lock.RLock()
+	extra, ok := mpi.Extra[namespace]
+	version := mpi.ExtraVersion[namespace]
+	mpi.lock.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+	raw, isRaw := extra.(json.RawMessage)
+	if !isRaw {
+		return extra, true, nil
+	}
+	raw, version, err := mpi.playerInfo.migrateExtra(namespace, version, raw)
+	if err != nil {
+		return nil, false, err
+	}
+	v := newValue()
+	err = json.Unmarshal(raw, v)
+	if err != nil {
+		// 保留原始数据, 避免结构变化时丢失
+		return nil, false, fmt.Errorf("解析 %s 的 Extra 数据失败: %w", namespace, err)
+	}
+	mpi.lock.Lock()
+	defer mpi.lock.Unlock()
+	// 解码期间可能已被其他调用缓存或 PutExtra 覆盖, 此时以已有的值为准
+	if current, ok := mpi.Extra[namespace].(json.RawMessage); !ok || !bytes.Equal(current, extra.(json.RawMessage)) {
+		return mpi.Extra[namespace], true, nil
+	}
+	mpi.Extra[namespace] = v
+	mpi.setExtraVersion(namespace, version)
+	return v, true, nil
+}
+
+// 返回 Extra 数据的副本, 修改后需调用 PutExtraT 写回, 数据不存在时 ok 为 false
+func GetExtraT[T any](mpi *MinecraftPlayerInfo, context pluginabi.PluginName) (*T, bool, error) {
+	extra, ok, err := mpi.loadExtra(context.Name(), func() any { return new(T) })
+	if !ok || err != nil {
+		return nil, false, err
+	}
+	// 缓存的值中的 map 与 slice 会与副本共享, 通过 JSON 深拷贝
+	mpi.lock.RLock()
+	data, err := json.Marshal(extra)
+	mpi.lock.RUnlock()
+	if err != nil {
+		return nil, false, fmt.Errorf("复制 %s 的 Extra 数据失败: %w", context.Name(), err)
+	}
+	v := new(T)
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return nil, false, fmt.Errorf("复制 %s 的 Extra 数据失败: %w", context.Name(), err)
+	}
+	return v, true, nil
+}
+
+func PutExtraT[T any](mpi *MinecraftPlayerInfo, context pluginabi.PluginName, v T) {
+	mpi.PutExtra(context, &v)
+}
+
+// 需持有 mpi.lock 的写锁
+func (mpi *MinecraftPlayerInfo) setExtraVersion(namespace string, version int) {
+	if version == 0 && mpi.ExtraVersion[namespace] == 0 {
+		return
+	}
+	if mpi.ExtraVersion == nil {
+		mpi.ExtraVersion = make(map[string]int)
+	}
+	mpi.ExtraVersion[namespace] = version
+}
+
+// 注册从 fromVersion 升级到 fromVersion+1 的迁移, 当前版本为已注册的最高版本 +1
+// 旧数据在 GetExtra 时依次迁移到当前版本
+func (pi *PlayerInfo) RegisterMigration(context pluginabi.PluginName, fromVersion int, fn func(old json.RawMessage) (json.RawMessage, error)) error {
+	if fromVersion < 0 {
+		return fmt.Errorf("invalid version: %d", fromVersion)
+	}
+	pi.migrationLock.Lock()
+	defer pi.migrationLock.Unlock()
+	if pi.migrations == nil {
+		pi.migrations = make(map[string]map[int]func(old json.RawMessage) (json.RawMessage, error))
+	}
+	namespace := context.Name()
+	if _, ok := pi.migrations[namespace]; !ok {
+		pi.migrations[namespace] = make(map[int]func(old json.RawMessage) (json.RawMessage, error))
+	}
+	if _, ok := pi.migrations[namespace][fromVersion]; ok {
+		return fmt.Errorf("migration from version %d exist", fromVersion)
+	}
+	pi.migrations[namespace][fromVersion] = fn
+	return nil
+}
+
+func (pi *PlayerInfo) extraVersion(namespace string) int {
+	if pi == nil {
+		return 0
+	}
+	pi.migrationLock.RLock()
+	defer pi.migrationLock.RUnlock()
+	version := 0
+	for from := range pi.migrations[namespace] {
+		version = max(version, from+1)
+	}
+	return version
+}
+
+func (pi *PlayerInfo) migrateExtra(namespace string, version int, raw json.RawMessage) (json.RawMessage, int, error) {
+	current := pi.extraVersion(namespace)
+	if version >= current {
+		return raw, version, nil
+	}
+	pi.migrationLock.RLock()
+	defer pi.migrationLock.RUnlock()
+	for ; version < current; version++ {
+		fn, ok := pi.migrations[namespace][version]
+		if !ok {
+			return nil, 0, fmt.Errorf("%s 缺少从版本 %d 升级的迁移", namespace, version)
+		}
+		migrated, err := fn(raw)
+		if err != nil {
+			return nil, 0, fmt.Errorf("迁移 %s 的 Extra 数据 (版本 %d) 失败: %w", namespace, version, err)
+		}
+		raw = migrated
+	}
+	return raw, current, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/nbt"
+)
+
+type ItemStack struct {
+	Slot  int
+	ID    string
+	Count int
+	Tag   json.RawMessage `json:",omitempty"`
+}
+
+func nbtNumber(v any) (int, bool) {
+	switch n := v.(type) {
+	case int8:
+		return int(n), true
+	case int16:
+		return int(n), true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+func parseInventory(inventory []any) ([]ItemStack, error) {
+	items := make([]ItemStack, 0, len(inventory))
+	for _, v := range inventory {
+		item, ok := v.(nbt.Compound)
+		if !ok {
+			return nil, fmt.Errorf("物品栏格式错误")
+		}
+		stack := ItemStack{}
+		stack.Slot, _ = nbtNumber(item["Slot"])
+		stack.ID, _ = item["id"].(string)
+		// 1.20.5 起 Count 改为 count, tag 改为 components
+		if count, ok := nbtNumber(item["Count"]); ok {
+			stack.Count = count
+		} else if count, ok := nbtNumber(item["count"]); ok {
+			stack.Count = count
+		}
+		tag, ok := item["tag"]
+		if !ok {
+			tag, ok = item["components"]
+		}
+		if ok {
+			rawTag, err := json.Marshal(tag)
+			if err != nil {
+				return nil, err
+			}
+			stack.Tag = rawTag
+		}
+		items = append(items, stack)
+	}
+	return items, nil
+}
+
+func (pi *PlayerInfo) GetInventory(player string) ([]ItemStack, error) {
+	if slices.Contains(pi.GetPlayerList(), player) {
+		inventoryRes := pi.RunCommand("data get entity " + player + " Inventory")
+		inventoryData := strings.SplitN(inventoryRes, ":", 2)
+		if len(inventoryData) != 2 {
+			return nil, fmt.Errorf("获取 NBT 失败")
+		}
+		inventory, err := nbt.ParseSNBT(inventoryData[1])
+		if err != nil {
+			return nil, err
+		}
+		list, ok := inventory.([]any)
+		if !ok {
+			return nil, fmt.Errorf("物品栏格式错误")
+		}
+		return parseInventory(list)
+	}
+	playerInfo, err := pi.GetPlayerInfo(player)
+	if err != nil {
+		return nil, err
+	}
+	playerInfo.lock.RLock()
+	uuid := playerInfo.UUID
+	playerInfo.lock.RUnlock()
+	cache, err := pi.readOfflinePlayerData(uuid)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := cache.data["Inventory"].([]any)
+	if !ok {
+		return []ItemStack{}, nil
+	}
+	return parseInventory(list)
+}
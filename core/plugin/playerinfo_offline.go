@@ -0,0 +1,110 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/nbt"
+)
+
+type playerInfo_OfflineCache struct {
+	mtime time.Time
+	data  nbt.Compound
+	info  *MinecraftPlayerInfo
+}
+
+var playerInfo_LegacyDimension = map[int32]string{
+	-1: "minecraft:the_nether",
+	0:  "minecraft:overworld",
+	1:  "minecraft:the_end",
+}
+
+func (pi *PlayerInfo) readOfflinePlayerData(uuid string) (*playerInfo_OfflineCache, error) {
+	path := filepath.Join(pi.WorldDir, "playerdata", uuid+".dat")
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	pi.offlineLock.Lock()
+	cache, ok := pi.offlineCache[uuid]
+	pi.offlineLock.Unlock()
+	if ok && cache.mtime.Equal(stat.ModTime()) {
+		return cache, nil
+	}
+	data, err := nbt.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := pi.parseOfflinePlayerInfo(uuid, data)
+	if err != nil {
+		return nil, err
+	}
+	cache = &playerInfo_OfflineCache{mtime: stat.ModTime(), data: data, info: info}
+	pi.offlineLock.Lock()
+	pi.offlineCache[uuid] = cache
+	pi.offlineLock.Unlock()
+	return cache, nil
+}
+
+func (pi *PlayerInfo) parseOfflinePlayerInfo(uuid string, data nbt.Compound) (*MinecraftPlayerInfo, error) {
+	info := &MinecraftPlayerInfo{UUID: uuid, Extra: make(MinecraftPlayerInfo_Extra)}
+	if rawUUID, ok := data["UUID"].([]int32); ok {
+		parsedUUID, err := pi.convertUUID(rawUUID)
+		if err == nil {
+			info.UUID = parsedUUID
+		}
+	}
+	pos, ok := data["Pos"].([]any)
+	if !ok || len(pos) != 3 {
+		return nil, fmt.Errorf("玩家数据缺少 Pos")
+	}
+	location := &MinecraftPosition{}
+	for i, v := range pos {
+		coord, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("玩家数据 Pos 格式错误")
+		}
+		location.Position[i] = coord
+	}
+	switch dim := data["Dimension"].(type) {
+	case string:
+		location.Dimension = dim
+	case int32:
+		location.Dimension = playerInfo_LegacyDimension[dim]
+	}
+	info.Location = location
+	pi.data.uuidMapLock.RLock()
+	info.Player = pi.data.UUIDMap[info.UUID]
+	pi.data.uuidMapLock.RUnlock()
+	return info, nil
+}
+
+func (pi *PlayerInfo) GetOfflinePlayerInfo(uuid string) (*MinecraftPlayerInfo, error) {
+	cache, err := pi.readOfflinePlayerData(uuid)
+	if err != nil {
+		return nil, err
+	}
+	location := *cache.info.Location
+	return &MinecraftPlayerInfo{
+		Player:   cache.info.Player,
+		UUID:     cache.info.UUID,
+		Location: &location,
+		Extra:    make(MinecraftPlayerInfo_Extra),
+	}, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// 部分服务端/模组在 list 输出的玩家名后附带延迟, 如 Steve (42ms) 或 Steve [42 ms]
+var PlayerListPing = regexp.MustCompile(`^(\S+)\s*[(\[]\s*(\d+)\s*ms\s*[)\]]$`)
+
+// PingCommand 的默认输出格式, 如 Steve: 42ms
+var PlayerPingEntry = regexp.MustCompile(`(\w+)\W+(\d+)\s*ms`)
+
+// 格式无效时使用默认格式
+func (pi *PlayerInfo) compilePingFormat(format string) *regexp.Regexp {
+	if format == "" {
+		return PlayerPingEntry
+	}
+	re, err := regexp.Compile(format)
+	if err == nil && re.NumSubexp() < 2 {
+		err = fmt.Errorf("需要至少两个捕获组")
+	}
+	if err != nil {
+		pi.Println(color.RedString("无效的延迟格式: "), color.MagentaString(err.Error()), color.RedString(", 使用默认格式"))
+		return PlayerPingEntry
+	}
+	return re
+}
+
+// 拆分 list 输出中的玩家名与延迟
+func (pi *PlayerInfo) parsePlayerListEntry(entry string) (player string, ping int, ok bool) {
+	player = strings.TrimSpace(entry)
+	match := PlayerListPing.FindStringSubmatch(player)
+	if match == nil {
+		return player, 0, false
+	}
+	ping, err := strconv.Atoi(match[2])
+	return match[1], ping, err == nil
+}
+
+func (pi *PlayerInfo) queryPing(pings map[string]int) {
+	if pi.config.PingCommand == "" {
+		return
+	}
+	for _, match := range pi.pingFormat.FindAllStringSubmatch(pi.RunCommand(pi.config.PingCommand), -1) {
+		if ping, err := strconv.Atoi(match[2]); err == nil {
+			pings[match[1]] = ping
+		}
+	}
+}
+
+// 服务端未提供延迟的玩家标记为不可用
+func (pi *PlayerInfo) updatePing(players []string, leftPlayers []string, pings map[string]int) {
+	pi.queryPing(pings)
+	for _, player := range players {
+		ping, ok := pings[player]
+		playerInfo := pi.getCachedPlayerInfo(player)
+		playerInfo.lock.Lock()
+		playerInfo.Ping, playerInfo.PingAvailable = ping, ok
+		playerInfo.lock.Unlock()
+	}
+	for _, player := range leftPlayers {
+		if playerInfo, ok := pi.LookupPlayerInfo(player); ok {
+			playerInfo.lock.Lock()
+			playerInfo.PingAvailable = false
+			playerInfo.lock.Unlock()
+		}
+	}
+}
+
+// 返回最近一次刷新玩家列表时的延迟 (毫秒), 玩家不在线或服务端不提供延迟时 ok 为 false
+func (pi *PlayerInfo) GetPing(player string) (int, bool) {
+	playerInfo, ok := pi.LookupPlayerInfo(player)
+	if !ok {
+		return 0, false
+	}
+	playerInfo.lock.RLock()
+	defer playerInfo.lock.RUnlock()
+	return playerInfo.Ping, playerInfo.PingAvailable
+}
@@ -0,0 +1,249 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// 玩家数据的持久化后端, 默认使用 JSON 文件
+type PlayerInfo_Store interface {
+	// 启动时调用, 返回需要预先载入内存的数据
+	Load() (*PlayerInfo_Storage, error)
+	// 缓存未命中时查询, 不存在时返回 nil
+	Lookup(player string) (*MinecraftPlayerInfo, error)
+	LookupUUID(uuid string) (player string, err error)
+	// dirty 为自上次保存以来调用过 Commit 的条目
+	Save(data *PlayerInfo_Storage, dirty []*MinecraftPlayerInfo) error
+}
+
+type PlayerInfo_JSONStore struct {
+	Path       string
+	LoadedFrom string // 最近一次 Load 实际读取的文件
+	primaryOK  bool   // 主文件可正常解析时才轮换为 .bak, 避免损坏的文件覆盖备份
+	lock       sync.Mutex
+}
+
+func (js *PlayerInfo_JSONStore) readFile(path string) (*PlayerInfo_Storage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	loaded := &PlayerInfo_Storage{}
+	err = json.Unmarshal(data, loaded)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return loaded, nil
+}
+
+// 主文件缺失或损坏时回退到 .bak
+func (js *PlayerInfo_JSONStore) Load() (*PlayerInfo_Storage, error) {
+	js.lock.Lock()
+	defer js.lock.Unlock()
+	loaded, err := js.readFile(js.Path)
+	if err == nil {
+		js.LoadedFrom = js.Path
+		js.primaryOK = true
+		return loaded, nil
+	}
+	js.primaryOK = false
+	backup, backupErr := js.readFile(js.Path + ".bak")
+	if backupErr != nil {
+		return nil, err
+	}
+	js.LoadedFrom = js.Path + ".bak"
+	return backup, nil
+}
+
+func (js *PlayerInfo_JSONStore) loadedFrom() string {
+	js.lock.Lock()
+	defer js.lock.Unlock()
+	return js.LoadedFrom
+}
+
+// JSON 文件启动时已全部载入
+func (js *PlayerInfo_JSONStore) Lookup(player string) (*MinecraftPlayerInfo, error) {
+	return nil, nil
+}
+
+func (js *PlayerInfo_JSONStore) LookupUUID(uuid string) (string, error) {
+	return "", nil
+}
+
+func (js *PlayerInfo_JSONStore) Save(data *PlayerInfo_Storage, _ []*MinecraftPlayerInfo) error {
+	data.RLock()
+	saveData, err := json.MarshalIndent(data, "", "\t")
+	data.RUnlock()
+	if err != nil {
+		return err
+	}
+	js.lock.Lock()
+	defer js.lock.Unlock()
+	err = writeFileAtomic(js.Path, saveData, js.primaryOK)
+	if err != nil {
+		return err
+	}
+	js.primaryOK = true
+	return nil
+}
+
+// SQLite 存储, 每个玩家一行, 按需查询
+// 需由调用方以 SQLite 驱动打开 DB, 如 sql.Open("sqlite", "data/playerinfo.db")
+type PlayerInfo_SQLiteStore struct {
+	DB *sql.DB
+	// 首次运行时导入的 JSON 文件, 为空则不迁移
+	MigrateFrom string
+}
+
+func (ss *PlayerInfo_SQLiteStore) Load() (*PlayerInfo_Storage, error) {
+	_, err := ss.DB.Exec(`CREATE TABLE IF NOT EXISTS players (
+		player TEXT PRIMARY KEY,
+		uuid TEXT NOT NULL DEFAULT '',
+		last_seen INTEGER NOT NULL DEFAULT 0,
+		data TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	_, err = ss.DB.Exec(`CREATE INDEX IF NOT EXISTS players_uuid ON players (uuid)`)
+	if err != nil {
+		return nil, err
+	}
+	err = ss.migrate()
+	if err != nil {
+		return nil, err
+	}
+	return &PlayerInfo_Storage{PlayerInfo: map[string]*MinecraftPlayerInfo{}, UUIDMap: map[string]string{}}, nil
+}
+
+func (ss *PlayerInfo_SQLiteStore) migrate() error {
+	if ss.MigrateFrom == "" {
+		return nil
+	}
+	var count int
+	err := ss.DB.QueryRow(`SELECT COUNT(*) FROM players`).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	loaded, err := (&PlayerInfo_JSONStore{Path: ss.MigrateFrom}).Load()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	players := make([]*MinecraftPlayerInfo, 0, len(loaded.PlayerInfo))
+	for player, playerInfo := range loaded.PlayerInfo {
+		if playerInfo != nil {
+			playerInfo.Player = player
+			players = append(players, playerInfo)
+		}
+	}
+	return ss.Save(loaded, players)
+}
+
+func (ss *PlayerInfo_SQLiteStore) Lookup(player string) (*MinecraftPlayerInfo, error) {
+	var data string
+	err := ss.DB.QueryRow(`SELECT data FROM players WHERE player = ?`, player).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	playerInfo := &MinecraftPlayerInfo{}
+	err = json.Unmarshal([]byte(data), playerInfo)
+	if err != nil {
+		return nil, err
+	}
+	return playerInfo, nil
+}
+
+// 同一 UUID 对应多个名称时取最近上线的
+func (ss *PlayerInfo_SQLiteStore) LookupUUID(uuid string) (string, error) {
+	var player string
+	err := ss.DB.QueryRow(`SELECT player FROM players WHERE uuid = ? ORDER BY last_seen DESC LIMIT 1`, strings.ToLower(uuid)).Scan(&player)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return player, err
+}
+
+func (ss *PlayerInfo_SQLiteStore) Save(_ *PlayerInfo_Storage, dirty []*MinecraftPlayerInfo) error {
+	if len(dirty) == 0 {
+		return nil
+	}
+	tx, err := ss.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`INSERT INTO players (player, uuid, last_seen, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (player) DO UPDATE SET uuid = excluded.uuid, last_seen = excluded.last_seen, data = excluded.data`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, playerInfo := range dirty {
+		data, err := json.Marshal(playerInfo)
+		if err != nil {
+			return err
+		}
+		playerInfo.lock.RLock()
+		player, uuid, lastSeen := playerInfo.Player, strings.ToLower(playerInfo.UUID), playerInfo.LastSeen.Unix()
+		playerInfo.lock.RUnlock()
+		_, err = stmt.Exec(player, uuid, lastSeen, string(data))
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// 先写入 <path>.tmp 再重命名, 避免写入中途崩溃损坏数据
+// backup 为 true 时将原文件保留为 <path>.bak
+func writeFileAtomic(path string, data []byte, backup bool) error {
+	tmpPath := path + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = tmpFile.Write(data)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if backup {
+		err = os.Rename(path, path+".bak")
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			os.Remove(tmpPath)
+			return err
+		}
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,250 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+)
+
+// 每次 Load 返回新的条目, 不写入文件
+type testPlayerInfoStore struct {
+	players []string
+}
+
+func (ts *testPlayerInfoStore) Load() (*PlayerInfo_Storage, error) {
+	loaded := &PlayerInfo_Storage{PlayerInfo: map[string]*MinecraftPlayerInfo{}, UUIDMap: map[string]string{}}
+	for _, player := range ts.players {
+		loaded.PlayerInfo[player] = &MinecraftPlayerInfo{Player: player}
+	}
+	return loaded, nil
+}
+
+func (ts *testPlayerInfoStore) Lookup(player string) (*MinecraftPlayerInfo, error) {
+	return nil, nil
+}
+
+func (ts *testPlayerInfoStore) LookupUUID(uuid string) (string, error) {
+	return "", nil
+}
+
+func (ts *testPlayerInfoStore) Save(data *PlayerInfo_Storage, dirty []*MinecraftPlayerInfo) error {
+	return nil
+}
+
+func newTestPlayerInfo(t *testing.T, players ...string) (*PlayerInfo, *testPluginManager) {
+	t.Helper()
+	pm := newTestPluginManager(t)
+	pi := &PlayerInfo{Mode: PlayerInfo_ModeOffline, Store: &testPlayerInfoStore{players: players}, WorldDir: pm.DataPath("world")}
+	if _, err := pm.RegisterPlugin(pi); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pi.Shutdown)
+	return pi, pm
+}
+
+// Load 与 GetPlayerInfo 的加锁顺序需一致, 否则并发时死锁
+func TestPlayerInfoLoadConcurrentGet(t *testing.T) {
+	var players []string
+	for i := range 8 {
+		players = append(players, fmt.Sprintf("player%d", i))
+	}
+	// 每次 Load 都会清空 UUID, 使 GetPlayerInfo 重新查询
+	pi, _ := newTestPlayerInfo(t, players...)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for range 200 {
+					if err := pi.Load(); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for range 200 {
+					if _, err := pi.GetPlayerInfo(fmt.Sprintf("player%d", i)); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("Load 与 GetPlayerInfo 死锁")
+	}
+}
+
+// 多个 goroutine 同时解码并缓存同一 Extra, 需配合 -race 运行
+func TestGetExtraConcurrent(t *testing.T) {
+	type extraData struct {
+		Count int
+		Items []string
+	}
+	context := &pluginabi.PluginNameWrapper{PluginName: "test"}
+	mpi := &MinecraftPlayerInfo{Player: "Steve", Extra: MinecraftPlayerInfo_Extra{"test": json.RawMessage(`{"Count":3,"Items":["a","b"]}`)}}
+	var wg sync.WaitGroup
+	for range 32 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				extra := &extraData{}
+				if err := mpi.GetExtra(context, extra); err != nil {
+					t.Error(err)
+					return
+				}
+				if extra.Count != 3 || len(extra.Items) != 2 {
+					t.Errorf("读取到错误的 Extra: %+v", extra)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestComputeOfflineUUID(t *testing.T) {
+	vectors := map[string]string{
+		"jeb_":  "a762f560-4fce-3236-812a-b80efff0b62b",
+		"Notch": "b50ad385-829d-3141-a216-7e7d7539ba7f",
+		"Steve": "5627dd98-e6be-3c21-b8a8-e92344183641",
+	}
+	for name, expected := range vectors {
+		if uuid := ComputeOfflineUUID(name); uuid != expected {
+			t.Errorf("ComputeOfflineUUID(%q) = %s, 应为 %s", name, uuid, expected)
+		}
+	}
+}
+
+func TestPlayerInfoPauseWithoutStart(t *testing.T) {
+	pi, _ := newTestPlayerInfo(t, "Steve")
+	pi.Pause()
+	pi.Pause()
+}
+
+func TestGetExtraTCopy(t *testing.T) {
+	type extraData struct {
+		Homes map[string]int
+	}
+	context := &pluginabi.PluginNameWrapper{PluginName: "test"}
+	mpi := &MinecraftPlayerInfo{Player: "Steve", Extra: MinecraftPlayerInfo_Extra{
+		"test":   json.RawMessage(`{"Homes":{"base":1}}`),
+		"broken": json.RawMessage(`{"Homes":[]}`),
+	}}
+	extra, ok, err := GetExtraT[extraData](mpi, context)
+	if err != nil || !ok {
+		t.Fatalf("读取 Extra 失败: %v %v", ok, err)
+	}
+	extra.Homes["base"] = 2
+	extra, _, _ = GetExtraT[extraData](mpi, context)
+	if extra.Homes["base"] != 1 {
+		t.Errorf("修改副本影响了缓存的值: %v", extra.Homes)
+	}
+	if _, ok, err := GetExtraT[extraData](mpi, &pluginabi.PluginNameWrapper{PluginName: "missing"}); ok || err != nil {
+		t.Errorf("不存在的 Extra 应返回 false, nil: %v %v", ok, err)
+	}
+	if _, _, err := GetExtraT[extraData](mpi, &pluginabi.PluginNameWrapper{PluginName: "broken"}); err == nil {
+		t.Error("无法解析的 Extra 应返回错误")
+	}
+}
+
+// UUID 查询失败时不应留下空 UUID 的条目
+func TestGetPlayerInfoUUIDFailure(t *testing.T) {
+	pi, _ := newTestPlayerInfo(t)
+	pi.offlineMode.Store(false)
+	pi.playerListLock.Lock()
+	pi.playerList = []string{"Steve"}
+	pi.playerListLock.Unlock()
+	if _, err := pi.GetPlayerInfo("Steve"); err == nil {
+		t.Fatal("UUID 查询失败时应返回错误")
+	}
+	if _, ok := pi.LookupPlayerInfo("Steve"); ok {
+		t.Error("UUID 查询失败后仍缓存了玩家信息")
+	}
+	pi.commitLock.Lock()
+	defer pi.commitLock.Unlock()
+	if _, ok := pi.dirty["Steve"]; ok {
+		t.Error("UUID 查询失败后仍保存了玩家信息")
+	}
+}
+
+// 启动时已在线的玩家不触发加入事件
+func TestPlayerListFirstRefresh(t *testing.T) {
+	pi, pm := newTestPlayerInfo(t)
+	online := "Steve, Alex"
+	pm.respond = func(cmd string) string {
+		if cmd == "list" {
+			return "There are 2 of a max of 20 players online: " + online
+		}
+		return ""
+	}
+	events := make(chan string, 8)
+	pi.RegisterJoinHandler(func(player string) { events <- "join " + player })
+	pi.RegisterLeaveHandler(func(player string) { events <- "leave " + player })
+	pi.updatePlayerList()
+	if list := pi.GetPlayerList(); len(list) != 2 {
+		t.Fatalf("玩家列表错误: %q", list)
+	}
+	online = "Steve, Bob"
+	pi.updatePlayerList()
+	received := []string{}
+	for range 2 {
+		select {
+		case event := <-events:
+			received = append(received, event)
+		case <-time.After(time.Second):
+			t.Fatalf("未收到全部事件: %q", received)
+		}
+	}
+	slices.Sort(received)
+	if !slices.Equal(received, []string{"join Bob", "leave Alex"}) {
+		t.Errorf("事件错误: %q", received)
+	}
+	select {
+	case event := <-events:
+		t.Errorf("多余的事件: %s", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestTeleportInvalidPlayer(t *testing.T) {
+	pi, pm := newTestPlayerInfo(t, "Steve")
+	pos := &MinecraftPosition{Dimension: "minecraft:overworld", Position: [3]float64{0, 64, 0}}
+	for _, player := range []string{"@a", "Steve run op Alex", "Steve\nop Alex", ""} {
+		if err := pi.Teleport(player, pos); err == nil {
+			t.Errorf("Teleport(%q) 应返回错误", player)
+		}
+	}
+	if commands := pm.takeCommands(); len(commands) != 0 {
+		t.Errorf("非法玩家名不应发送命令: %q", commands)
+	}
+}
@@ -15,6 +15,8 @@
 package pluginabi
 
 import (
+	"time"
+
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/manager"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -48,16 +50,87 @@ func (p *PluginNameWrapper) DisplayName() string {
 	return p.PluginDisplayName
 }
 
+const (
+	ServerFlavorVanilla  = "vanilla"
+	ServerFlavorForge    = "forge"
+	ServerFlavorNeoForge = "neoforge"
+	ServerFlavorFabric   = "fabric"
+	ServerFlavorPaper    = "paper"
+	ServerFlavorSpigot   = "spigot"
+)
+
+// 由启动日志解析, 接管已运行的服务器时可能为空
+type ServerInfo struct {
+	Version string
+	Flavor  string
+}
+
+type LogLine struct {
+	Time    string
+	Thread  string
+	Level   string // INFO/WARN/ERROR...
+	Message string
+	Raw     string
+	Locked  bool // 是否为命令执行期间的输出
+}
+
+// 为空的条件不参与过滤
+type LogFilter struct {
+	Levels []string
+	Prefix string
+}
+
+// 可选, 守护进程退出时按初始化逆序调用
+type Shutdowner interface {
+	Shutdown()
+}
+
+// 由 BasePlugin 实现, 插件暂停时取消其全部定时任务
+type TaskCanceller interface {
+	CancelAllTasks()
+}
+
+// 可选, 实现后可在运行时重新读取配置文件
+type ConfigReloader interface {
+	ReloadConfig() error
+}
+
+type PluginState struct {
+	Name        string
+	DisplayName string
+	Enabled     bool // 为 false 时已被手动禁用, 服务器启动时不会启动
+	Started     bool
+	Core        bool // 内置插件, 不可禁用
+}
+
 type PluginManager interface {
 	Printf(scope string, format string, a ...any) (n int, err error)
 	Println(scope string, a ...any) (n int, err error)
 	RegisterLogProcesser(context PluginName, process func(logmsg string, iscommandrespone bool)) (channel chan *manager.MessageResponse)
+	RegisterStructuredLogProcesser(context PluginName, filter LogFilter, process func(line LogLine))
 	RegisterManagerMessageChannel(skipRegister bool) (channel chan *manager.MessageResponse)
 	RegisterPlugin(plugin Plugin) (p Plugin, err error)
 	GetPlugin(pluginName string) Plugin
+	EnablePlugin(pluginName string) error
+	DisablePlugin(pluginName string) error
+	ReloadPluginConfig(pluginName string) error
+	PluginDisabled(pluginName string) bool
+	ListPlugins() []PluginState
 	UnRegisterManagerMessageChannel(channel chan *manager.MessageResponse)
 
 	RunCommand(cmd string) string
+	RunCommandAsync(cmd string) <-chan string
+	RunCommandTimeout(cmd string, d time.Duration) (string, error)
+	CommandQueueDepth() int
+
+	DataPath(name string) string
+
+	Publish(topic string, payload any)
+	Subscribe(context PluginName, topic string, handler func(payload any))
+	// 未指定 topic 时取消该插件的全部订阅
+	Unsubscribe(context PluginName, topics ...string)
+
+	ServerInfo() ServerInfo
 
 	Status(opts ...grpc.CallOption) (*manager.StatusResponse, error)
 	Stop(opts ...grpc.CallOption) (*emptypb.Empty, error)
@@ -0,0 +1,102 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+type TaskHandle uint64
+
+func (bp *BasePlugin) addTask() (TaskHandle, chan struct{}) {
+	bp.taskLock.Lock()
+	defer bp.taskLock.Unlock()
+	if bp.tasks == nil {
+		bp.tasks = make(map[TaskHandle]chan struct{})
+	}
+	bp.taskId++
+	stop := make(chan struct{})
+	bp.tasks[bp.taskId] = stop
+	return bp.taskId, stop
+}
+
+func (bp *BasePlugin) removeTask(handle TaskHandle) {
+	bp.taskLock.Lock()
+	defer bp.taskLock.Unlock()
+	delete(bp.tasks, handle)
+}
+
+func (bp *BasePlugin) runTask(fn func()) {
+	defer func() {
+		if err := recover(); err != nil {
+			bp.Println(color.RedString("定时任务执行失败: "), color.MagentaString(fmt.Sprint(err)))
+		}
+	}()
+	fn()
+}
+
+// 插件暂停时任务会被自动取消, 应在 Start 中注册
+func (bp *BasePlugin) ScheduleRepeating(interval time.Duration, fn func()) TaskHandle {
+	handle, stop := bp.addTask()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				bp.runTask(fn)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return handle
+}
+
+func (bp *BasePlugin) ScheduleOnce(delay time.Duration, fn func()) TaskHandle {
+	handle, stop := bp.addTask()
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			bp.removeTask(handle)
+			bp.runTask(fn)
+		case <-stop:
+		}
+	}()
+	return handle
+}
+
+func (bp *BasePlugin) Cancel(handle TaskHandle) {
+	bp.taskLock.Lock()
+	defer bp.taskLock.Unlock()
+	if stop, ok := bp.tasks[handle]; ok {
+		close(stop)
+		delete(bp.tasks, handle)
+	}
+}
+
+func (bp *BasePlugin) CancelAllTasks() {
+	bp.taskLock.Lock()
+	defer bp.taskLock.Unlock()
+	for handle, stop := range bp.tasks {
+		close(stop)
+		delete(bp.tasks, handle)
+	}
+}
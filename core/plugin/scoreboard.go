@@ -17,8 +17,12 @@ package plugin
 import (
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
+	"os"
 	"regexp"
 	"slices"
 	"strconv"
@@ -27,38 +31,102 @@ import (
 	"time"
 
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
 	"github.com/cespare/xxhash/v2"
 	"github.com/fatih/color"
 	"github.com/samber/lo"
 	"golang.org/x/exp/maps"
 )
 
+type TriggerMode int
+
+const (
+	TriggerModeAdd TriggerMode = iota // /trigger <obj> 或 /trigger <obj> add <n>
+	TriggerModeSet                    // /trigger <obj> set <n>
+)
+
+type TriggerEvent struct {
+	Player string
+	Value  int // Add 时为增量, 不带参数的 /trigger 为 1; Set 时为设置的值
+	Mode   TriggerMode
+}
+
 type MinecraftTrigger struct {
-	Trigger    func(player string, value int)
+	Trigger    func(player string, value int) // 旧接口, 不区分 set/add, 不带参数时 value 为 0
+	OnTrigger  func(event TriggerEvent)       // 设置后代替 Trigger 调用
 	Selector   string
 	Time       int64
+	Cooldown   time.Duration // 同一玩家两次触发的最小间隔, 0 为不限制
+	Min        int           // Max > Min 时, 超出 [Min, Max] 的值不会触发回调
+	Max        int
 	createTime time.Time
+	players    []string // 非空时只对这些玩家启用, 代替 Selector
+}
+
+// 触发后重新启用的范围, 指定了玩家时只重新启用触发者
+func (t *MinecraftTrigger) enableSelector(player string) string {
+	if len(t.players) > 0 {
+		return player
+	}
+	return t.Selector
+}
+
+func (t *MinecraftTrigger) inRange(value int) bool {
+	return t.Max <= t.Min || (value >= t.Min && value <= t.Max)
+}
+
+func (t *MinecraftTrigger) fire(event TriggerEvent, legacyValue int) {
+	if t.OnTrigger != nil {
+		t.OnTrigger(event)
+	} else if t.Trigger != nil {
+		t.Trigger(event.Player, legacyValue)
+	}
 }
 
 const MaxTriggerCount = 1024
 
+type ScoreWatcher func(player string, old int64, new int64)
+
 type ScoreboardCore struct {
 	BasePlugin
-	score       map[string]map[string]int64
-	scorelist   []string
-	trigger     map[string]MinecraftTrigger
-	triggerInfo *regexp.Regexp
-	tlock       sync.RWMutex
-	lock        sync.RWMutex
-	debounce    *time.Timer
+	score        map[string]map[string]int64
+	scorelist    []string
+	trigger      map[string]MinecraftTrigger
+	triggerFire  map[string]map[string]time.Time
+	triggerInfo  *regexp.Regexp
+	tlock        sync.RWMutex
+	lock         sync.RWMutex
+	debounce     *time.Timer
+	dlock        sync.Mutex
+	persisted    map[string]map[string]int64
+	commitTimer  *time.Timer
+	commitLock   sync.Mutex
+	watcher      map[string][]ScoreWatcher
+	wlock        sync.RWMutex
+	scale        map[string]int64
+	displayText  map[string]string
+	paused       bool
+	displaySlots map[string][]scoreboardCore_DisplayClaim // 显示位 -> 按优先级排序的占用者
+	displaySeq   uint64
+	displayLock  sync.Mutex
 }
 
 func (sc *ScoreboardCore) Init(pm pluginabi.PluginManager) error {
 	sc.BasePlugin.Init(pm, sc)
 	sc.score = make(map[string]map[string]int64)
 	sc.trigger = make(map[string]MinecraftTrigger)
-	sc.triggerInfo = regexp.MustCompile(`.*?\]:(?: \[[^\]]+\])? ?\[(\w+): ?Triggered ?\[(.*?)\] ?(?:\(set value to (\d+)\)|\(added (\d+) to value\))?\]`)
+	sc.triggerFire = make(map[string]map[string]time.Time)
+	sc.watcher = make(map[string][]ScoreWatcher)
+	sc.scale = make(map[string]int64)
+	sc.displayText = make(map[string]string)
+	sc.displaySlots = make(map[string][]scoreboardCore_DisplayClaim)
+	sc.triggerInfo = regexp.MustCompile(`.*?\]:(?: \[[^\]]+\])? ?\[(\w+): ?Triggered ?\[(.*?)\] ?(?:\(set value to (-?\d+)\)|\(added (-?\d+) to value\))?\]`)
 	pm.RegisterLogProcesser(sc, sc.processTrigger)
+	sc.RegisterCommand("scoreexport", sc.exportCommand, OpLevel(2))
+	err := sc.Load()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		sc.Println(color.RedString("加载存储的记分板数据失败"))
+	}
 	return nil
 }
 
@@ -69,6 +137,7 @@ func (sc *ScoreboardCore) cleanExpiredTrigger() {
 	for key, value := range sc.trigger {
 		if now.Sub(value.createTime).Hours() > 1 || value.Time == 0 {
 			delete(sc.trigger, key)
+			delete(sc.triggerFire, key)
 			cleanupTransaction = append(cleanupTransaction,
 				fmt.Sprintf("scoreboard objectives remove %s", key),
 			)
@@ -82,6 +151,7 @@ func (sc *ScoreboardCore) cleanExpiredTrigger() {
 		overflowTriggerList := triggerList[min(len(triggerList), MaxTriggerCount):]
 		for _, key := range overflowTriggerList {
 			delete(sc.trigger, key)
+			delete(sc.triggerFire, key)
 			cleanupTransaction = append(cleanupTransaction,
 				fmt.Sprintf("scoreboard objectives remove %s", key),
 			)
@@ -101,29 +171,68 @@ func (sc *ScoreboardCore) processTrigger(logText string, _ bool) {
 	value := 0
 	player := strings.TrimSpace(triggerInfo[1])
 	trigger := strings.TrimSpace(triggerInfo[2])
+	event := TriggerEvent{Player: player, Value: 1, Mode: TriggerModeAdd}
 	if triggerInfo[3] != "" {
 		parsedvalue, _ := strconv.ParseInt(triggerInfo[3], 10, 0)
 		value = int(parsedvalue)
+		event.Value, event.Mode = value, TriggerModeSet
 	} else if triggerInfo[4] != "" {
 		parsedvalue, _ := strconv.ParseInt(triggerInfo[4], 10, 0)
 		value = int(parsedvalue)
+		event.Value = value
 	}
-	sc.tlock.RLock()
+	limited := false
+	sc.tlock.Lock()
 	triggerEntry, ok := sc.trigger[trigger]
-	sc.tlock.RUnlock()
+	if ok && len(triggerEntry.players) > 0 && !slices.Contains(triggerEntry.players, player) {
+		ok = false
+	}
+	if ok && triggerEntry.Cooldown > 0 {
+		now := time.Now()
+		if lastFire, fired := sc.triggerFire[trigger][player]; fired && now.Sub(lastFire) < triggerEntry.Cooldown {
+			limited = true
+		} else {
+			if _, ok := sc.triggerFire[trigger]; !ok {
+				sc.triggerFire[trigger] = make(map[string]time.Time)
+			}
+			sc.triggerFire[trigger][player] = now
+		}
+	}
+	sc.tlock.Unlock()
 	triggerEntry.Time--
 	if ok && triggerEntry.Time != 0 {
-		sc.RunCommand(fmt.Sprintf("scoreboard players enable %s %s", triggerEntry.Selector, trigger))
-		go triggerEntry.Trigger(player, value)
+		sc.RunCommand(fmt.Sprintf("scoreboard players enable %s %s", triggerEntry.enableSelector(player), trigger))
+		if limited {
+			sc.Println(
+				color.YellowString("玩家 "),
+				color.GreenString(player),
+				color.YellowString(" 触发 "),
+				color.CyanString(trigger),
+				color.RedString(" 过于频繁, 已忽略"),
+			)
+		} else if !triggerEntry.inRange(event.Value) {
+			sc.Println(
+				color.YellowString("玩家 "),
+				color.GreenString(player),
+				color.YellowString(" 触发 "),
+				color.CyanString(trigger),
+				color.RedString(" 的值 %d 超出范围 [%d, %d], 已忽略", event.Value, triggerEntry.Min, triggerEntry.Max),
+			)
+		} else {
+			go triggerEntry.fire(event, value)
+		}
 	}
 	sc.cleanExpiredTrigger()
 }
 
-func (sc *ScoreboardCore) ensureScoreboard(context pluginabi.PluginName, name string, criterion string, displayname string) {
+func (sc *ScoreboardCore) ensureScoreboard(context pluginabi.PluginName, name string, criterion string, displayname string, scale ...int64) {
 	name = fmt.Sprintf("%s_%s", sc.getNamespace(context), name)
-	sc.lock.RLock()
+	sc.lock.Lock()
+	if len(scale) > 0 && scale[0] > 1 {
+		sc.scale[name] = scale[0]
+	}
 	ok := slices.Contains(sc.scorelist, name)
-	sc.lock.RUnlock()
+	sc.lock.Unlock()
 	if ok {
 		return
 	}
@@ -131,7 +240,7 @@ func (sc *ScoreboardCore) ensureScoreboard(context pluginabi.PluginName, name st
 		color.YellowString("插件 "),
 		color.BlueString(context.DisplayName()),
 		color.YellowString(" 注册了一个 "),
-		color.GreenString(name[9:]),
+		color.GreenString(sc.shortName(context, name)),
 		color.YellowString("("),
 		color.HiCyanString(displayname),
 		color.YellowString(")"),
@@ -143,16 +252,113 @@ func (sc *ScoreboardCore) ensureScoreboard(context pluginabi.PluginName, name st
 	sc.RunCommand(fmt.Sprintf(`scoreboard objectives add %s %s %s`, name, criterion, displayname))
 	sc.lock.Lock()
 	sc.scorelist = append(sc.scorelist, name)
+	sc.displayText[name] = sc.plainDisplayName(displayname)
 	sc.lock.Unlock()
 	sc.requestSync()
 }
 
-func (sc *ScoreboardCore) getNamespace(context pluginabi.PluginName) string {
-	xhash := xxhash.Sum64String(context.Name())
+func (sc *ScoreboardCore) removeScoreboard(names ...string) {
+	commandTransaction := []string{}
+	sc.lock.Lock()
+	for _, name := range names {
+		sc.scorelist = slices.DeleteFunc(sc.scorelist, func(item string) bool {
+			return item == name
+		})
+		for _, playerscope := range sc.score {
+			delete(playerscope, name)
+		}
+		for _, playerscope := range sc.persisted {
+			delete(playerscope, name)
+		}
+		delete(sc.scale, name)
+		delete(sc.displayText, name)
+		commandTransaction = append(commandTransaction, fmt.Sprintf(`scoreboard objectives remove %s`, name))
+	}
+	sc.lock.Unlock()
+	sc.wlock.Lock()
+	for _, name := range names {
+		delete(sc.watcher, name)
+	}
+	sc.wlock.Unlock()
+	if len(commandTransaction) > 0 {
+		sc.RunCommand(strings.Join(commandTransaction, "\n"))
+		sc.releaseDisplay(names...)
+		sc.requestCommit()
+	}
+}
+
+func (sc *ScoreboardCore) RemoveObjective(context pluginabi.PluginName, name string) error {
+	name = fmt.Sprintf("%s_%s", sc.getNamespace(context), name)
+	sc.lock.RLock()
+	ok := slices.Contains(sc.scorelist, name)
+	sc.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("scoreboard not exist")
+	}
+	sc.Println(
+		color.YellowString("插件 "),
+		color.BlueString(context.DisplayName()),
+		color.YellowString(" 移除了 "),
+		color.GreenString(sc.shortName(context, name)),
+		color.YellowString("记分板"),
+	)
+	sc.removeScoreboard(name)
+	return nil
+}
+
+func (sc *ScoreboardCore) RemoveAllObjectives(context pluginabi.PluginName) {
+	prefix := sc.getNamespace(context) + "_"
+	sc.lock.RLock()
+	names := lo.Filter(sc.scorelist, func(item string, index int) bool {
+		return strings.HasPrefix(item, prefix)
+	})
+	sc.lock.RUnlock()
+	if len(names) == 0 {
+		return
+	}
+	sc.Println(
+		color.YellowString("清理插件 "),
+		color.BlueString(context.DisplayName()),
+		color.YellowString(" 的%d个记分板", len(names)),
+	)
+	sc.removeScoreboard(names...)
+}
+
+func (sc *ScoreboardCore) plainDisplayName(displayname string) string {
+	var text string
+	if json.Unmarshal([]byte(displayname), &text) == nil {
+		return text
+	}
+	var msg []tellraw.Message
+	if json.Unmarshal([]byte(displayname), &msg) == nil {
+		return strings.Join(lo.Map(msg, func(item tellraw.Message, index int) string {
+			return item.Text
+		}), "")
+	}
+	return displayname
+}
+
+// 插件名哈希得到的命名空间, 记分项与队伍名共用
+func pluginNamespace(pluginName string) string {
+	xhash := xxhash.Sum64String(pluginName)
 	bhash := binary.BigEndian.AppendUint64([]byte{}, xhash)
 	return base64.RawURLEncoding.EncodeToString(bhash[4:])[:5]
 }
 
+func (sc *ScoreboardCore) getNamespace(context pluginabi.PluginName) string {
+	return pluginNamespace(context.Name())
+}
+
+// 返回带命名空间的记分项名称, 可用于 tellraw 的 score 组件
+func (sc *ScoreboardCore) ObjectiveName(context pluginabi.PluginName, name string) string {
+	return fmt.Sprintf("%s_%s", sc.getNamespace(context), name)
+}
+
+// ObjectiveName 的逆操作, 去掉命名空间前缀
+func (sc *ScoreboardCore) shortName(context pluginabi.PluginName, name string) string {
+	return strings.TrimPrefix(name, sc.getNamespace(context)+"_")
+}
+
 func (sc *ScoreboardCore) Name() string {
 	return "ScoreboardCore"
 }
@@ -161,25 +367,109 @@ func (sc *ScoreboardCore) DisplayName() string {
 	return "记分板核心"
 }
 
-var ScoreboardTrackedPlayer = regexp.MustCompile(`There are \d tracked .*?:\s?(.*)`)
-var ScoreboardTrackedPlayerScore = regexp.MustCompile(`^.*? has (\d+)`)
+var ScoreboardTrackedPlayer = regexp.MustCompile(`There are \d+ tracked .*?:\s?(.*)`)
+var ScoreboardTrackedPlayerScore = regexp.MustCompile(`^.*? has (-?\d+)`)
+var ScoreboardCommandRejected = regexp.MustCompile(`Unknown or incomplete command|Incorrect argument`)
+var ScoreboardPlayerScoreEntry = regexp.MustCompile(`\[(.*?)\]: (-?\d+)`)
 
 func (sc *ScoreboardCore) requestSync() {
-	if sc.debounce != nil {
-		sc.debounce.Reset(1 * time.Second)
+	sc.lock.RLock()
+	paused := sc.paused
+	sc.lock.RUnlock()
+	if paused {
+		return
+	}
+	sc.dlock.Lock()
+	defer sc.dlock.Unlock()
+	if sc.debounce == nil {
+		sc.debounce = time.AfterFunc(1*time.Second, sc.syncScore)
+		return
 	}
-	sc.debounce = time.AfterFunc(1*time.Second, sc.syncScore)
+	sc.debounce.Reset(1 * time.Second)
 }
 
-func (sc *ScoreboardCore) displayScoreboard(context pluginabi.PluginName, name string, slot string) {
+var ScoreboardRenderType = []string{"integer", "hearts"}
+
+func (sc *ScoreboardCore) SetRenderType(context pluginabi.PluginName, name string, rendertype string) error {
+	if !slices.Contains(ScoreboardRenderType, rendertype) {
+		return fmt.Errorf("unknown render type: %s", rendertype)
+	}
 	name = fmt.Sprintf("%s_%s", sc.getNamespace(context), name)
 	sc.lock.RLock()
 	ok := slices.Contains(sc.scorelist, name)
 	sc.lock.RUnlock()
 	if !ok {
-		return
+		return fmt.Errorf("scoreboard not exist")
+	}
+	sc.RunCommand(fmt.Sprintf(`scoreboard objectives modify %s rendertype %s`, name, rendertype))
+	return nil
+}
+
+type NumberFormat_Type string
+
+var (
+	NumberFormat_Reset  NumberFormat_Type = ""
+	NumberFormat_Blank  NumberFormat_Type = "blank"
+	NumberFormat_Styled NumberFormat_Type = "styled"
+	NumberFormat_Fixed  NumberFormat_Type = "fixed"
+)
+
+type NumberFormat struct {
+	Type  NumberFormat_Type
+	Style tellraw.Message   // styled 使用, 忽略 Text
+	Fixed []tellraw.Message // fixed 使用
+}
+
+func (nf NumberFormat) argument() (string, error) {
+	switch nf.Type {
+	case NumberFormat_Reset:
+		return "", nil
+	case NumberFormat_Blank:
+		return " blank", nil
+	case NumberFormat_Styled:
+		style := nf.Style
+		style.Text = ""
+		bStyle, err := json.Marshal(style)
+		if err != nil {
+			return "", err
+		}
+		return " styled " + string(bStyle), nil
+	case NumberFormat_Fixed:
+		if len(nf.Fixed) == 0 {
+			return "", fmt.Errorf("empty fixed number format")
+		}
+		bFixed, err := json.Marshal(nf.Fixed)
+		if err != nil {
+			return "", err
+		}
+		return " fixed " + string(bFixed), nil
+	}
+	return "", fmt.Errorf("unknown number format: %s", nf.Type)
+}
+
+func (sc *ScoreboardCore) SetNumberFormat(context pluginabi.PluginName, name string, format NumberFormat) error {
+	argument, err := format.argument()
+	if err != nil {
+		return err
 	}
-	sc.RunCommand(fmt.Sprintf(`scoreboard objectives setdisplay %s %s`, slot, name))
+	name = fmt.Sprintf("%s_%s", sc.getNamespace(context), name)
+	sc.lock.RLock()
+	ok := slices.Contains(sc.scorelist, name)
+	sc.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("scoreboard not exist")
+	}
+	res := sc.RunCommand(fmt.Sprintf(`scoreboard objectives modify %s numberformat%s`, name, argument))
+	if ScoreboardCommandRejected.MatchString(res) {
+		sc.Println(
+			color.YellowString("服务器不支持记分板数字格式, 忽略插件 "),
+			color.BlueString(context.DisplayName()),
+			color.YellowString(" 对 "),
+			color.GreenString(sc.shortName(context, name)),
+			color.YellowString(" 的设置"),
+		)
+	}
+	return nil
 }
 
 func (sc *ScoreboardCore) scoreAction(context pluginabi.PluginName, player string, name string, action string, count int64) {
@@ -195,6 +485,7 @@ func (sc *ScoreboardCore) scoreAction(context pluginabi.PluginName, player strin
 
 func (sc *ScoreboardCore) getOneScore(context pluginabi.PluginName, player string, name string) int64 {
 	sc.syncOneScore(context, player, name)
+	name = fmt.Sprintf("%s_%s", sc.getNamespace(context), name)
 	sc.lock.RLock()
 	defer sc.lock.RUnlock()
 	if playerscope, ok := sc.score[player]; ok {
@@ -205,11 +496,59 @@ func (sc *ScoreboardCore) getOneScore(context pluginabi.PluginName, player strin
 	return 0
 }
 
+func (sc *ScoreboardCore) getScale(name string) int64 {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+	if scale, ok := sc.scale[name]; ok {
+		return scale
+	}
+	return 1
+}
+
+func (sc *ScoreboardCore) scoreActionFloat(context pluginabi.PluginName, player string, name string, action string, count float64) {
+	scale := sc.getScale(fmt.Sprintf("%s_%s", sc.getNamespace(context), name))
+	scaled := math.Round(count * float64(scale))
+	if scaled > math.MaxInt32 || scaled < math.MinInt32 || math.IsNaN(scaled) {
+		sc.Println(
+			color.YellowString("插件 "),
+			color.BlueString(context.DisplayName()),
+			color.YellowString(" 写入记分板 "),
+			color.GreenString(name),
+			color.YellowString(" 的数值 "),
+			color.RedString("%f", count),
+			color.YellowString(" 超出范围"),
+		)
+		if math.IsNaN(scaled) {
+			return
+		}
+		scaled = math.Max(math.MinInt32, math.Min(math.MaxInt32, scaled))
+	}
+	sc.scoreAction(context, player, name, action, int64(scaled))
+}
+
+func (sc *ScoreboardCore) getOneScoreFloat(context pluginabi.PluginName, player string, name string) float64 {
+	score := sc.getOneScore(context, player, name)
+	return float64(score) / float64(sc.getScale(fmt.Sprintf("%s_%s", sc.getNamespace(context), name)))
+}
+
+func (sc *ScoreboardCore) getAllScoreFloat() (scores map[string]map[string]float64) {
+	scores = map[string]map[string]float64{}
+	for player, playerscope := range sc.getAllScore() {
+		scores[player] = make(map[string]float64)
+		for name, score := range playerscope {
+			scores[player][name] = float64(score) / float64(sc.getScale(name))
+		}
+	}
+	return scores
+}
+
 func (sc *ScoreboardCore) getAllScore() (scores map[string]map[string]int64) {
 	scores = map[string]map[string]int64{}
 	sc.syncScore()
 	sc.lock.RLock()
-	maps.Copy(scores, sc.score)
+	for player, playerscope := range sc.score {
+		scores[player] = maps.Clone(playerscope)
+	}
 	sc.lock.RUnlock()
 	return scores
 }
@@ -230,9 +569,75 @@ func (sc *ScoreboardCore) syncOneScore(context pluginabi.PluginName, player stri
 	if len(scoreMatch) == 2 {
 		scoreValue, err := strconv.ParseInt(scoreMatch[1], 10, 64)
 		if err == nil {
-			sc.score[player][name] = scoreValue
+			sc.updateScore(player, name, scoreValue)
 		}
 	}
+	sc.requestCommit()
+}
+
+func (sc *ScoreboardCore) EnsureObjective(context pluginabi.PluginName, name string, criterion string, displayName string) {
+	sc.ensureScoreboard(context, name, criterion, displayName)
+}
+
+func (sc *ScoreboardCore) EnsureFixedObjective(context pluginabi.PluginName, name string, criterion string, displayName string, scale int64) {
+	sc.ensureScoreboard(context, name, criterion, displayName, scale)
+}
+
+func (sc *ScoreboardCore) SetScoreFloat(context pluginabi.PluginName, player string, name string, value float64) {
+	sc.scoreActionFloat(context, player, name, "set", value)
+}
+
+func (sc *ScoreboardCore) AddScoreFloat(context pluginabi.PluginName, player string, name string, value float64) {
+	sc.scoreActionFloat(context, player, name, "add", value)
+}
+
+func (sc *ScoreboardCore) GetScoreFloat(context pluginabi.PluginName, player string, name string) float64 {
+	return sc.getOneScoreFloat(context, player, name)
+}
+
+func (sc *ScoreboardCore) GetAllScoresFloat() map[string]map[string]float64 {
+	return sc.getAllScoreFloat()
+}
+
+type LeaderboardEntry struct {
+	Player string
+	Score  int64
+}
+
+func (sc *ScoreboardCore) Leaderboard(context pluginabi.PluginName, name string, n int) []LeaderboardEntry {
+	name = fmt.Sprintf("%s_%s", sc.getNamespace(context), name)
+	leaderboard := []LeaderboardEntry{}
+	for player, playerscope := range sc.getAllScore() {
+		if score, ok := playerscope[name]; ok {
+			leaderboard = append(leaderboard, LeaderboardEntry{Player: player, Score: score})
+		}
+	}
+	slices.SortFunc(leaderboard, func(a LeaderboardEntry, b LeaderboardEntry) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Player, b.Player)
+	})
+	return leaderboard[:min(max(n, 0), len(leaderboard))]
+}
+
+func (sc *ScoreboardCore) SetScore(context pluginabi.PluginName, player string, name string, value int64) {
+	sc.scoreAction(context, player, name, "set", value)
+}
+
+func (sc *ScoreboardCore) AddScore(context pluginabi.PluginName, player string, name string, value int64) {
+	sc.scoreAction(context, player, name, "add", value)
+}
+
+func (sc *ScoreboardCore) GetScore(context pluginabi.PluginName, player string, name string) int64 {
+	return sc.getOneScore(context, player, name)
+}
+
+func (sc *ScoreboardCore) GetAllScores() map[string]map[string]int64 {
+	return sc.getAllScore()
 }
 
 func (sc *ScoreboardCore) registerTrigger(context pluginabi.PluginName, trigger ...MinecraftTrigger) (name []string) {
@@ -251,7 +656,7 @@ func (sc *ScoreboardCore) registerTrigger(context pluginabi.PluginName, trigger
 			}
 		}
 		triggerEntry.createTime = time.Now()
-		if triggerEntry.Selector == "" {
+		if triggerEntry.Selector == "" && len(triggerEntry.players) == 0 {
 			triggerEntry.Selector = "@a"
 		}
 		if triggerEntry.Time == 0 {
@@ -259,10 +664,14 @@ func (sc *ScoreboardCore) registerTrigger(context pluginabi.PluginName, trigger
 		}
 		sc.trigger[triggername] = triggerEntry
 		name = append(name, triggername)
-		commandTransaction = append(commandTransaction,
-			fmt.Sprintf("scoreboard objectives add %s trigger", triggername),
-			fmt.Sprintf("scoreboard players enable %s %s", triggerEntry.Selector, triggername),
-		)
+		commandTransaction = append(commandTransaction, fmt.Sprintf("scoreboard objectives add %s trigger", triggername))
+		if len(triggerEntry.players) > 0 {
+			for _, player := range triggerEntry.players {
+				commandTransaction = append(commandTransaction, fmt.Sprintf("scoreboard players enable %s %s", player, triggername))
+			}
+		} else {
+			commandTransaction = append(commandTransaction, fmt.Sprintf("scoreboard players enable %s %s", triggerEntry.Selector, triggername))
+		}
 	}
 	sc.tlock.Unlock()
 	sc.Println(
@@ -293,24 +702,62 @@ func (sc *ScoreboardCore) clearTrigger() {
 }
 
 func (sc *ScoreboardCore) syncScore() {
+	sc.lock.RLock()
+	paused := sc.paused
+	sc.lock.RUnlock()
+	if paused {
+		return
+	}
 	trackedPlayersStr := sc.RunCommand("scoreboard players list")
 	if ScoreboardTrackedPlayer.MatchString(trackedPlayersStr) {
 		trackedPlayers := lo.Map(strings.Split(ScoreboardTrackedPlayer.FindStringSubmatch(trackedPlayersStr)[1], ","), func(item string, index int) string {
 			return strings.TrimSpace(item)
 		})
+		defer sc.requestCommit()
 		sc.lock.Lock()
 		defer sc.lock.Unlock()
-		for _, player := range trackedPlayers {
+		// scoreboard players list <player> 只输出记分项的显示名称，显示名称重复的记分项仍需逐个查询
+		displayIndex := make(map[string]string)
+		ambiguous := []string{}
+		for _, score := range sc.scorelist {
+			text := sc.displayText[score]
+			if other, ok := displayIndex[text]; ok {
+				if other != "" {
+					ambiguous = append(ambiguous, other)
+					displayIndex[text] = ""
+				}
+				ambiguous = append(ambiguous, score)
+				continue
+			}
+			displayIndex[text] = score
+		}
+		// 一次性入队, 避免逐条等待
+		scoreListResults := lo.Map(trackedPlayers, func(player string, _ int) <-chan string {
+			return sc.RunCommandAsync(fmt.Sprintf(`scoreboard players list %s`, player))
+		})
+		for i, player := range trackedPlayers {
 			if _, ok := sc.score[player]; !ok {
 				sc.score[player] = make(map[string]int64)
 			}
-			for _, score := range sc.scorelist {
+			scoreListResult := <-scoreListResults[i]
+			// 原版 RCON 返回的多行输出没有换行, 直接在整段输出中查找
+			for _, entryMatch := range ScoreboardPlayerScoreEntry.FindAllStringSubmatch(scoreListResult, -1) {
+				score, ok := displayIndex[entryMatch[1]]
+				if !ok || score == "" {
+					continue
+				}
+				scoreValue, err := strconv.ParseInt(entryMatch[2], 10, 64)
+				if err == nil {
+					sc.updateScore(player, score, scoreValue)
+				}
+			}
+			for _, score := range ambiguous {
 				scoreResult := sc.RunCommand(fmt.Sprintf(`scoreboard players get %s %s`, player, score))
 				scoreMatch := ScoreboardTrackedPlayerScore.FindStringSubmatch(scoreResult)
 				if len(scoreMatch) == 2 {
 					scoreValue, err := strconv.ParseInt(scoreMatch[1], 10, 64)
 					if err == nil {
-						sc.score[player][score] = scoreValue
+						sc.updateScore(player, score, scoreValue)
 					}
 				}
 			}
@@ -319,5 +766,141 @@ func (sc *ScoreboardCore) syncScore() {
 }
 
 func (sc *ScoreboardCore) Start() {
+	sc.lock.Lock()
+	sc.paused = false
+	sc.lock.Unlock()
 	sc.clearTrigger()
 }
+
+func (sc *ScoreboardCore) Pause() {
+	sc.lock.Lock()
+	sc.paused = true
+	sc.lock.Unlock()
+	sc.dlock.Lock()
+	if sc.debounce != nil {
+		sc.debounce.Stop()
+	}
+	sc.dlock.Unlock()
+	sc.tlock.Lock()
+	cleanupTransaction := lo.Map(maps.Keys(sc.trigger), func(item string, index int) string {
+		return fmt.Sprintf("scoreboard objectives remove %s", item)
+	})
+	sc.trigger = make(map[string]MinecraftTrigger)
+	sc.triggerFire = make(map[string]map[string]time.Time)
+	sc.tlock.Unlock()
+	if len(cleanupTransaction) > 0 {
+		sc.RunCommand(strings.Join(cleanupTransaction, "\n"))
+	}
+}
+
+func (sc *ScoreboardCore) Shutdown() {
+	sc.commitLock.Lock()
+	if sc.commitTimer != nil {
+		sc.commitTimer.Stop()
+		sc.commitTimer = nil
+	}
+	sc.commitLock.Unlock()
+	err := sc.Commit()
+	if err != nil {
+		sc.Println(color.RedString("保存记分板数据失败: "), color.MagentaString(err.Error()))
+	}
+}
+
+// 调用时需持有 sc.lock
+func (sc *ScoreboardCore) updateScore(player string, name string, value int64) {
+	if _, ok := sc.score[player]; !ok {
+		sc.score[player] = make(map[string]int64)
+	}
+	sc.reconcileScore(player, name, value)
+	old := sc.score[player][name]
+	sc.score[player][name] = value
+	if old == value {
+		return
+	}
+	sc.wlock.RLock()
+	watchers := sc.watcher[name]
+	sc.wlock.RUnlock()
+	for _, watcher := range watchers {
+		go watcher(player, old, value)
+	}
+}
+
+// 玩家第一次被同步到时 old 为 0
+func (sc *ScoreboardCore) RegisterScoreWatcher(context pluginabi.PluginName, name string, cb ScoreWatcher) {
+	name = fmt.Sprintf("%s_%s", sc.getNamespace(context), name)
+	sc.wlock.Lock()
+	defer sc.wlock.Unlock()
+	sc.watcher[name] = append(sc.watcher[name], cb)
+}
+
+// 调用时需持有 sc.lock
+func (sc *ScoreboardCore) reconcileScore(player string, name string, value int64) {
+	playerscope, ok := sc.persisted[player]
+	if !ok {
+		return
+	}
+	persistedValue, ok := playerscope[name]
+	if !ok {
+		return
+	}
+	delete(playerscope, name)
+	if len(playerscope) == 0 {
+		delete(sc.persisted, player)
+	}
+	if persistedValue != value {
+		sc.Println(
+			color.YellowString("记分板 "),
+			color.GreenString(name),
+			color.YellowString(" 中玩家 "),
+			color.GreenString(player),
+			color.YellowString(" 的分数与存储不一致: "),
+			color.RedString("%d", persistedValue),
+			color.YellowString(" -> "),
+			color.GreenString("%d", value),
+		)
+	}
+}
+
+func (sc *ScoreboardCore) requestCommit() {
+	sc.commitLock.Lock()
+	defer sc.commitLock.Unlock()
+	if sc.commitTimer != nil {
+		sc.commitTimer.Stop()
+	}
+	sc.commitTimer = time.AfterFunc(5*time.Second, func() {
+		err := sc.Commit()
+		if err != nil {
+			sc.Println(color.RedString("保存记分板数据失败: "), color.MagentaString(err.Error()))
+		}
+	})
+}
+
+func (sc *ScoreboardCore) Load() error {
+	data, err := os.ReadFile(sc.DataPath("scoreboard.json"))
+	if err != nil {
+		return err
+	}
+	score := make(map[string]map[string]int64)
+	err = json.Unmarshal(data, &score)
+	if err != nil {
+		return err
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	sc.persisted = make(map[string]map[string]int64)
+	for player, playerscope := range score {
+		sc.score[player] = playerscope
+		sc.persisted[player] = maps.Clone(playerscope)
+	}
+	return nil
+}
+
+func (sc *ScoreboardCore) Commit() error {
+	sc.lock.RLock()
+	saveData, err := json.MarshalIndent(sc.score, "", "\t")
+	sc.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sc.DataPath("scoreboard.json"), saveData, 0644)
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"slices"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"github.com/fatih/color"
+)
+
+// 每个插件在一个显示位上最多占用一个记分项
+type scoreboardCore_DisplayClaim struct {
+	owner       string
+	displayName string
+	objective   string
+	priority    int
+	seq         uint64
+}
+
+// 优先级高者占用显示位, 相同优先级时后设置者优先
+func (sc *ScoreboardCore) sortDisplayClaims(claims []scoreboardCore_DisplayClaim) {
+	slices.SortFunc(claims, func(a, b scoreboardCore_DisplayClaim) int {
+		if a.priority != b.priority {
+			return b.priority - a.priority
+		}
+		return int(b.seq) - int(a.seq)
+	})
+}
+
+// 显示位占用者变化时重新设置, 调用时需持有 sc.displayLock
+func (sc *ScoreboardCore) applyDisplay(slot string, previous string) {
+	current := ""
+	if claims := sc.displaySlots[slot]; len(claims) > 0 {
+		current = claims[0].objective
+	}
+	if current == previous {
+		return
+	}
+	if current == "" {
+		sc.RunCommand(fmt.Sprintf(`scoreboard objectives setdisplay %s`, slot))
+		return
+	}
+	sc.RunCommand(fmt.Sprintf(`scoreboard objectives setdisplay %s %s`, slot, current))
+}
+
+func (sc *ScoreboardCore) displayTop(slot string) (scoreboardCore_DisplayClaim, bool) {
+	claims := sc.displaySlots[slot]
+	if len(claims) == 0 {
+		return scoreboardCore_DisplayClaim{}, false
+	}
+	return claims[0], true
+}
+
+func (sc *ScoreboardCore) displayScoreboard(context pluginabi.PluginName, name string, slot string, priority int) {
+	name = fmt.Sprintf("%s_%s", sc.getNamespace(context), name)
+	sc.lock.RLock()
+	ok := slices.Contains(sc.scorelist, name)
+	sc.lock.RUnlock()
+	if !ok {
+		return
+	}
+	sc.displayLock.Lock()
+	defer sc.displayLock.Unlock()
+	previous, occupied := sc.displayTop(slot)
+	sc.displaySeq++
+	claim := scoreboardCore_DisplayClaim{owner: context.Name(), displayName: context.DisplayName(), objective: name, priority: priority, seq: sc.displaySeq}
+	claims := slices.DeleteFunc(sc.displaySlots[slot], func(item scoreboardCore_DisplayClaim) bool {
+		return item.owner == claim.owner
+	})
+	claims = append(claims, claim)
+	sc.sortDisplayClaims(claims)
+	sc.displaySlots[slot] = claims
+	top := claims[0]
+	switch {
+	case top.owner != claim.owner:
+		sc.Println(color.YellowString("插件 "), color.BlueString(claim.displayName), color.YellowString(" 的记分板等待显示位 "), color.GreenString(slot), color.YellowString(", 当前由插件 "), color.BlueString(top.displayName), color.YellowString(" 占用"))
+	case occupied && previous.owner != claim.owner:
+		sc.Println(color.YellowString("插件 "), color.BlueString(claim.displayName), color.YellowString(" 取代插件 "), color.BlueString(previous.displayName), color.YellowString(" 占用显示位 "), color.GreenString(slot))
+	}
+	sc.applyDisplay(slot, previous.objective)
+}
+
+// 释放插件占用的显示位, 由排队中优先级最高的记分项接替
+func (sc *ScoreboardCore) ClearDisplay(context pluginabi.PluginName, slot string) {
+	sc.displayLock.Lock()
+	defer sc.displayLock.Unlock()
+	previous, _ := sc.displayTop(slot)
+	sc.displaySlots[slot] = slices.DeleteFunc(sc.displaySlots[slot], func(item scoreboardCore_DisplayClaim) bool {
+		return item.owner == context.Name()
+	})
+	sc.applyDisplay(slot, previous.objective)
+}
+
+// 记分项被移除后游戏会清空其显示位, 需由下一个记分项接替
+func (sc *ScoreboardCore) releaseDisplay(names ...string) {
+	sc.displayLock.Lock()
+	defer sc.displayLock.Unlock()
+	for slot, claims := range sc.displaySlots {
+		previous, _ := sc.displayTop(slot)
+		claims = slices.DeleteFunc(claims, func(item scoreboardCore_DisplayClaim) bool {
+			return slices.Contains(names, item.objective)
+		})
+		sc.displaySlots[slot] = claims
+		if slices.Contains(names, previous.objective) {
+			previous.objective = ""
+		}
+		sc.applyDisplay(slot, previous.objective)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/command"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
+	"github.com/samber/lo"
+	"golang.org/x/exp/maps"
+)
+
+var scoreExportCommand = command.New("scoreexport",
+	command.Arg("objective", command.String),
+)
+
+func (sc *ScoreboardCore) formatScore(name string, value int64) string {
+	scale := sc.getScale(name)
+	if scale == 1 {
+		return strconv.FormatInt(value, 10)
+	}
+	return strconv.FormatFloat(float64(value)/float64(scale), 'f', -1, 64)
+}
+
+// 导出记分项为 CSV, name 为完整的记分项名称
+// name 为空时导出全部记分项, 每个记分项一列
+func (sc *ScoreboardCore) ExportCSV(name string, w io.Writer) error {
+	scores := sc.getAllScore()
+	sc.lock.RLock()
+	objectives := slices.Clone(sc.scorelist)
+	sc.lock.RUnlock()
+	writer := csv.NewWriter(w)
+	if name != "" {
+		if !slices.Contains(objectives, name) {
+			return fmt.Errorf("记分项 %s 不存在", name)
+		}
+		entries := []LeaderboardEntry{}
+		for player, playerscope := range scores {
+			if score, ok := playerscope[name]; ok {
+				entries = append(entries, LeaderboardEntry{Player: player, Score: score})
+			}
+		}
+		slices.SortFunc(entries, func(a LeaderboardEntry, b LeaderboardEntry) int {
+			if a.Score != b.Score {
+				if a.Score > b.Score {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(a.Player, b.Player)
+		})
+		writer.Write([]string{"player", "score"})
+		for _, entry := range entries {
+			writer.Write([]string{entry.Player, sc.formatScore(name, entry.Score)})
+		}
+	} else {
+		slices.Sort(objectives)
+		players := maps.Keys(scores)
+		slices.Sort(players)
+		writer.Write(append([]string{"player"}, objectives...))
+		for _, player := range players {
+			row := lo.Map(objectives, func(objective string, _ int) string {
+				if score, ok := scores[player][objective]; ok {
+					return sc.formatScore(objective, score)
+				}
+				return ""
+			})
+			writer.Write(append([]string{player}, row...))
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// 导出到数据目录的 exports 下, 返回文件路径
+func (sc *ScoreboardCore) ExportCSVFile(name string) (string, error) {
+	// 记分项名称会拼入文件名, 需在创建文件前校验
+	if name != "" {
+		if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+			return "", fmt.Errorf("无效的记分项名称: %q", name)
+		}
+		sc.lock.RLock()
+		exist := slices.Contains(sc.scorelist, name)
+		sc.lock.RUnlock()
+		if !exist {
+			return "", fmt.Errorf("记分项 %s 不存在", name)
+		}
+	}
+	dir := sc.DataPath("exports")
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	prefix := name
+	if prefix == "" {
+		prefix = "all"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.csv", prefix, time.Now().Format("20060102-150405")))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	// 写入 BOM, 便于表格软件识别 UTF-8
+	_, err = file.WriteString("\ufeff")
+	if err == nil {
+		err = sc.ExportCSV(name, file)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+func (sc *ScoreboardCore) exportCommand(player string, args ...string) {
+	values, err := scoreExportCommand.Parse(args)
+	if err != nil {
+		if usage, ok := err.(*command.UsageError); ok {
+			sc.Tellraw(player, usage.Tellraw())
+		}
+		return
+	}
+	path, err := sc.ExportCSVFile(values.String("objective"))
+	if err != nil {
+		sc.Tellraw(player, []tellraw.Message{{Text: "导出失败: ", Color: tellraw.Red}, {Text: err.Error(), Color: tellraw.Yellow}})
+		return
+	}
+	sc.Tellraw(player, []tellraw.Message{{Text: "记分板已导出到 ", Color: tellraw.Green}, {Text: path, Color: tellraw.Yellow}})
+}
@@ -0,0 +1,194 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+)
+
+func newTestScoreboardCore(t *testing.T) (*ScoreboardCore, *testPluginManager) {
+	t.Helper()
+	pm := newTestPluginManager(t)
+	sc := &ScoreboardCore{}
+	if _, err := pm.RegisterPlugin(sc); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sc.Pause()
+		sc.Shutdown()
+	})
+	return sc, pm
+}
+
+// 不同插件的同名记分项互不影响
+func TestScoreboardNamespaceIsolation(t *testing.T) {
+	sc, pm := newTestScoreboardCore(t)
+	a := &pluginabi.PluginNameWrapper{PluginName: "PluginA"}
+	b := &pluginabi.PluginNameWrapper{PluginName: "PluginB"}
+	nameA, nameB := sc.ObjectiveName(a, "kills"), sc.ObjectiveName(b, "kills")
+	if nameA == nameB {
+		t.Fatalf("记分项名称冲突: %s", nameA)
+	}
+	pm.respond = func(cmd string) string {
+		switch cmd {
+		case "scoreboard players get Steve " + nameA:
+			return fmt.Sprintf("Steve has 5 [%s]", nameA)
+		case "scoreboard players get Steve " + nameB:
+			return fmt.Sprintf("Steve has 7 [%s]", nameB)
+		}
+		return ""
+	}
+	sc.EnsureObjective(a, "kills", "dummy", `"Kills"`)
+	sc.EnsureObjective(b, "kills", "dummy", `"Kills"`)
+	commands := pm.takeCommands()
+	for _, name := range []string{nameA, nameB} {
+		if !slices.Contains(commands, fmt.Sprintf(`scoreboard objectives add %s dummy "Kills"`, name)) {
+			t.Errorf("未创建记分项 %s: %q", name, commands)
+		}
+	}
+	if score := sc.GetScore(a, "Steve", "kills"); score != 5 {
+		t.Errorf("PluginA 的分数为 %d, 应为 5", score)
+	}
+	if score := sc.GetScore(b, "Steve", "kills"); score != 7 {
+		t.Errorf("PluginB 的分数为 %d, 应为 7", score)
+	}
+	sc.RemoveAllObjectives(a)
+	if err := sc.RemoveObjective(a, "kills"); err == nil {
+		t.Error("PluginA 的记分项未被移除")
+	}
+	if score := sc.GetScore(b, "Steve", "kills"); score != 7 {
+		t.Errorf("移除 PluginA 的记分项后 PluginB 的分数为 %d, 应为 7", score)
+	}
+}
+
+func TestScoreboardPauseRemovesTriggers(t *testing.T) {
+	sc, pm := newTestScoreboardCore(t)
+	context := &pluginabi.PluginNameWrapper{PluginName: "PluginA"}
+	names := sc.registerTrigger(context, MinecraftTrigger{Trigger: func(string, int) {}}, MinecraftTrigger{Trigger: func(string, int) {}})
+	pm.takeCommands()
+	sc.Pause()
+	commands := strings.Split(strings.Join(pm.takeCommands(), "\n"), "\n")
+	for _, name := range names {
+		if !slices.Contains(commands, "scoreboard objectives remove "+name) {
+			t.Errorf("未移除触发器 %s: %q", name, commands)
+		}
+	}
+	sc.tlock.RLock()
+	defer sc.tlock.RUnlock()
+	if len(sc.trigger) != 0 {
+		t.Errorf("暂停后仍有 %d 个触发器", len(sc.trigger))
+	}
+}
+
+// 连续的同步请求只触发一次 syncScore
+func TestScoreboardRequestSyncDebounce(t *testing.T) {
+	sc, pm := newTestScoreboardCore(t)
+	var syncCount atomic.Int32
+	pm.respond = func(cmd string) string {
+		if cmd == "scoreboard players list" {
+			syncCount.Add(1)
+		}
+		return ""
+	}
+	for range 100 {
+		sc.requestSync()
+	}
+	time.Sleep(1500 * time.Millisecond)
+	if count := syncCount.Load(); count != 1 {
+		t.Errorf("syncScore 执行了 %d 次, 应为 1 次", count)
+	}
+}
+
+// /trigger 的负数参数需能被解析, 范围检查才能拒绝它
+func TestScoreboardTriggerNegativeValue(t *testing.T) {
+	sc, _ := newTestScoreboardCore(t)
+	context := &pluginabi.PluginNameWrapper{PluginName: "PluginA"}
+	events := make(chan TriggerEvent, 4)
+	names := sc.registerTrigger(context,
+		MinecraftTrigger{OnTrigger: func(event TriggerEvent) { events <- event }},
+		MinecraftTrigger{OnTrigger: func(event TriggerEvent) { events <- event }, Min: 0, Max: 10},
+	)
+	sc.processTrigger(fmt.Sprintf("[12:00:00] [Server thread/INFO]: [Steve: Triggered [%s] (set value to -5)]", names[0]), false)
+	select {
+	case event := <-events:
+		if event.Value != -5 || event.Mode != TriggerModeSet {
+			t.Errorf("解析结果错误: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("负数 set 未触发回调")
+	}
+	sc.processTrigger(fmt.Sprintf("[12:00:00] [Server thread/INFO]: [Steve: Triggered [%s] (added -3 to value)]", names[0]), false)
+	select {
+	case event := <-events:
+		if event.Value != -3 || event.Mode != TriggerModeAdd {
+			t.Errorf("解析结果错误: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("负数 add 未触发回调")
+	}
+	sc.processTrigger(fmt.Sprintf("[12:00:00] [Server thread/INFO]: [Steve: Triggered [%s] (set value to -5)]", names[1]), false)
+	select {
+	case event := <-events:
+		t.Errorf("超出范围的值触发了回调: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestScoreboardShortName(t *testing.T) {
+	sc, _ := newTestScoreboardCore(t)
+	context := &pluginabi.PluginNameWrapper{PluginName: "PluginA"}
+	for _, name := range []string{"kills", "k", "deaths_total"} {
+		if short := sc.shortName(context, sc.ObjectiveName(context, name)); short != name {
+			t.Errorf("shortName(ObjectiveName(%q)) = %q", name, short)
+		}
+	}
+}
+
+// 指定了玩家的触发器只对这些玩家启用
+func TestScoreboardTriggerPlayers(t *testing.T) {
+	sc, pm := newTestScoreboardCore(t)
+	context := &pluginabi.PluginNameWrapper{PluginName: "PluginA"}
+	events := make(chan TriggerEvent, 4)
+	names := sc.registerTrigger(context, MinecraftTrigger{OnTrigger: func(event TriggerEvent) { events <- event }, Time: 2, players: []string{"Steve"}})
+	commands := strings.Split(strings.Join(pm.takeCommands(), "\n"), "\n")
+	if !slices.Contains(commands, "scoreboard players enable Steve "+names[0]) || slices.Contains(commands, "scoreboard players enable @a "+names[0]) {
+		t.Errorf("触发器启用范围错误: %q", commands)
+	}
+	sc.processTrigger(fmt.Sprintf("[12:00:00] [Server thread/INFO]: [Alex: Triggered [%s] (set value to 1)]", names[0]), false)
+	select {
+	case event := <-events:
+		t.Errorf("非收件人触发了回调: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+	sc.processTrigger(fmt.Sprintf("[12:00:00] [Server thread/INFO]: [Steve: Triggered [%s] (set value to 1)]", names[0]), false)
+	select {
+	case event := <-events:
+		if event.Player != "Steve" {
+			t.Errorf("触发者错误: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("收件人未触发回调")
+	}
+	if commands := pm.takeCommands(); !slices.Contains(commands, "scoreboard players enable Steve "+names[0]) {
+		t.Errorf("未重新启用触发器: %q", commands)
+	}
+}
@@ -16,18 +16,24 @@ package plugin
 
 import (
 	"fmt"
-	"regexp"
+	"slices"
 	"strings"
 	"sync"
 
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
 	"github.com/fatih/color"
+	"github.com/samber/lo"
+	"golang.org/x/exp/maps"
 )
 
 type SimpleCommand struct {
 	BasePlugin
-	playerCommand    *regexp.Regexp
 	registerCommands map[string]func(string, ...string)
+	completers       map[string]func(args []string) []string
+	permissions      map[string]CommandPermission
+	owners           map[string]string // 命令 -> 注册的插件名
+	config           SimpleCommand_Config
 	lock             sync.RWMutex
 }
 
@@ -36,18 +42,52 @@ func (sp *SimpleCommand) Init(pm pluginabi.PluginManager) (err error) {
 	if err != nil {
 		return err
 	}
-	pm.RegisterLogProcesser(sp, sp.processCommand)
-	sp.playerCommand = regexp.MustCompile(`.*?\]:(?: \[[^\]]+\])? <(.*?)>.*?!!(.*)`)
 	sp.registerCommands = make(map[string]func(string, ...string))
+	sp.completers = make(map[string]func(args []string) []string)
+	sp.permissions = make(map[string]CommandPermission)
+	sp.owners = make(map[string]string)
+	err = sp.ReloadConfig()
+	if err != nil {
+		sp.Println(color.RedString("读取权限配置失败: "), color.MagentaString(err.Error()))
+	}
+	// 由 PlayerInfo 解析聊天消息, 支持自定义聊天格式
+	err = sp.RegisterChatHandler(sp.processCommand)
+	if err != nil {
+		sp.Println(color.RedString("注册聊天处理失败, 无法响应玩家命令: "), color.MagentaString(err.Error()))
+	}
+	sp.RegisterCommand(sp, "plugins", sp.pluginsCommand, OpLevel(3))
+	sp.RegisterCommandCompleter(sp, "plugins", sp.pluginsCompleter)
+	return nil
+}
+
+// 读取失败时保留原有配置
+func (sp *SimpleCommand) ReloadConfig() error {
+	config := SimpleCommand_Config{Permissions: map[string][]string{}}
+	err := sp.LoadConfig(&config)
+	if err != nil {
+		return err
+	}
+	if config.ChatPrefix != "" {
+		sp.Println(color.YellowString("额外的命令前缀: "), color.GreenString(config.ChatPrefix))
+	}
+	sp.lock.Lock()
+	sp.config = config
+	sp.lock.Unlock()
 	return nil
 }
 
-func (sp *SimpleCommand) RegisterCommand(context pluginabi.PluginName, command string, commandFunc func(string, ...string)) error {
+func (sp *SimpleCommand) RegisterCommand(context pluginabi.PluginName, command string, commandFunc func(string, ...string), opts ...CommandOption) error {
 	sp.lock.Lock()
 	defer sp.lock.Unlock()
 	if _, ok := sp.registerCommands[command]; !ok {
 		sp.Println(color.YellowString("插件 "), color.BlueString(context.DisplayName()), color.YellowString(" 注册了一条新命令: "), color.GreenString(command))
 		sp.registerCommands[command] = commandFunc
+		perm := CommandPermission{}
+		for _, opt := range opts {
+			opt(&perm)
+		}
+		sp.permissions[command] = perm
+		sp.owners[command] = context.Name()
 	} else {
 		sp.Println(color.YellowString("插件 "), color.BlueString(context.DisplayName()), color.RedString(" 尝试注册已注册的命令: "), color.GreenString(command))
 		return fmt.Errorf("command exist")
@@ -55,21 +95,102 @@ func (sp *SimpleCommand) RegisterCommand(context pluginabi.PluginName, command s
 	return nil
 }
 
-func (sp *SimpleCommand) processCommand(logText string, _ bool) {
-	cmdInfo := sp.playerCommand.FindStringSubmatch(logText)
-	if len(cmdInfo) < 3 {
+func (sp *SimpleCommand) RegisterCommandCompleter(context pluginabi.PluginName, command string, completer func(args []string) []string) error {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	if _, ok := sp.completers[command]; ok {
+		sp.Println(color.YellowString("插件 "), color.BlueString(context.DisplayName()), color.RedString(" 尝试注册已注册的补全: "), color.GreenString(command))
+		return fmt.Errorf("completer exist")
+	}
+	sp.completers[command] = completer
+	return nil
+}
+
+// 返回补全后的完整命令行 (不含 !! 前缀)
+func (sp *SimpleCommand) Complete(line string) []string {
+	parts := strings.Split(line, " ")
+	partial := parts[len(parts)-1]
+	var candidates []string
+	sp.lock.RLock()
+	completer, ok := sp.completers[parts[0]]
+	if len(parts) == 1 {
+		candidates = maps.Keys(sp.registerCommands)
+	}
+	sp.lock.RUnlock()
+	if len(parts) > 1 && ok {
+		candidates = completer(parts[1:])
+	}
+	prefix := strings.Join(parts[:len(parts)-1], " ")
+	if prefix != "" {
+		prefix += " "
+	}
+	result := lo.FilterMap(candidates, func(candidate string, _ int) (string, bool) {
+		return prefix + candidate, strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(partial))
+	})
+	slices.Sort(result)
+	return result
+}
+
+// 游戏内无法 Tab 补全, 输入 !!? <命令> 获取可点击的补全建议
+func (sp *SimpleCommand) suggest(player string, line string) {
+	candidates := sp.Complete(line)
+	if len(candidates) == 0 {
+		sp.Tellraw(player, []tellraw.Message{{Text: "没有可用的补全", Color: tellraw.Red}})
 		return
 	}
-	player := strings.TrimSpace(cmdInfo[1])
-	rawCommand := strings.TrimSpace(cmdInfo[2])
+	msg := []tellraw.Message{{Text: "补全: ", Color: tellraw.Aqua}}
+	for i, candidate := range candidates {
+		if i != 0 {
+			msg = append(msg, tellraw.Message{Text: " "})
+		}
+		msg = append(msg, tellraw.Message{
+			Text:       candidate,
+			Color:      tellraw.Green,
+			ClickEvent: &tellraw.ClickEvent{Action: tellraw.SuggestCommand, Value: "!!" + candidate},
+		})
+	}
+	sp.Tellraw(player, msg)
+}
+
+// 聊天消息以 !! 或配置的前缀开头时作为命令执行
+func (sp *SimpleCommand) processCommand(player string, message string) {
+	var rawCommand string
+	sp.lock.RLock()
+	chatPrefix := sp.config.ChatPrefix
+	sp.lock.RUnlock()
+	switch {
+	case strings.HasPrefix(message, "!!"):
+		rawCommand = message[2:]
+	case chatPrefix != "" && strings.HasPrefix(message, chatPrefix):
+		rawCommand = message[len(chatPrefix):]
+	default:
+		return
+	}
+	rawCommand = strings.TrimSpace(rawCommand)
 	commandPart := strings.Split(rawCommand, " ")
 	command := commandPart[0]
+	if command == "?" {
+		sp.suggest(player, strings.Join(commandPart[1:], " "))
+		return
+	}
 	sp.lock.RLock()
 	commandFunc, ok := sp.registerCommands[command]
+	perm := sp.permissions[command]
+	owner := sp.owners[command]
 	sp.lock.RUnlock()
-	if ok {
-		go commandFunc(player, commandPart[1:]...)
+	if !ok {
+		return
+	}
+	if sp.pm.PluginDisabled(owner) {
+		sp.Tellraw(player, []tellraw.Message{{Text: "!!" + command, Color: tellraw.Yellow}, {Text: " 所属的插件已被禁用", Color: tellraw.Red}})
+		return
+	}
+	// 聊天处理已在独立的 goroutine 中执行
+	if !sp.hasPermission(player, perm) {
+		sp.Tellraw(player, []tellraw.Message{{Text: "你没有权限执行 ", Color: tellraw.Red}, {Text: "!!" + command, Color: tellraw.Yellow}})
+		return
 	}
+	commandFunc(player, commandPart[1:]...)
 }
 
 func (sp *SimpleCommand) Name() string {
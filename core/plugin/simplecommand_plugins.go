@@ -0,0 +1,95 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
+	"github.com/samber/lo"
+)
+
+// !!plugins [enable|disable|reload <插件名>]
+func (sp *SimpleCommand) pluginsCommand(player string, args ...string) {
+	if len(args) == 0 {
+		sp.listPlugins(player)
+		return
+	}
+	usage := []tellraw.Message{{Text: "用法: ", Color: tellraw.Aqua}, {Text: "!!plugins [enable|disable|reload <插件名>]", Color: tellraw.Yellow}}
+	if len(args) != 2 {
+		sp.Tellraw(player, usage)
+		return
+	}
+	var err error
+	var result string
+	switch args[0] {
+	case "enable":
+		err, result = sp.pm.EnablePlugin(args[1]), " 已启用"
+	case "disable":
+		err, result = sp.pm.DisablePlugin(args[1]), " 已禁用"
+	case "reload":
+		err, result = sp.pm.ReloadPluginConfig(args[1]), " 配置已重新加载"
+	default:
+		sp.Tellraw(player, usage)
+		return
+	}
+	if err != nil {
+		sp.Tellraw(player, []tellraw.Message{{Text: err.Error(), Color: tellraw.Red}})
+		return
+	}
+	sp.Tellraw(player, []tellraw.Message{{Text: "插件 ", Color: tellraw.Aqua}, {Text: args[1], Color: tellraw.Green}, {Text: result, Color: tellraw.Aqua}})
+}
+
+func (sp *SimpleCommand) listPlugins(player string) {
+	sp.Tellraw(player, []tellraw.Message{{Text: "============ 插件列表 ============", Color: tellraw.Green}})
+	for _, state := range sp.pm.ListPlugins() {
+		msg := []tellraw.Message{{Text: state.DisplayName, Color: tellraw.Aqua}, {Text: " (" + state.Name + ") ", Color: tellraw.Gray}}
+		switch {
+		case !state.Enabled:
+			msg = append(msg, tellraw.Message{
+				Text:       "已禁用",
+				Color:      tellraw.Red,
+				ClickEvent: &tellraw.ClickEvent{Action: tellraw.SuggestCommand, Value: "!!plugins enable " + state.Name},
+			})
+		case state.Started:
+			msg = append(msg, tellraw.Message{Text: "运行中", Color: tellraw.Green})
+		default:
+			msg = append(msg, tellraw.Message{Text: "未启动", Color: tellraw.Yellow})
+		}
+		if state.Core {
+			msg = append(msg, tellraw.Message{Text: " [内置]", Color: tellraw.Gray})
+		} else if state.Enabled {
+			msg[len(msg)-1].ClickEvent = &tellraw.ClickEvent{Action: tellraw.SuggestCommand, Value: "!!plugins disable " + state.Name}
+		}
+		sp.Tellraw(player, msg)
+	}
+}
+
+func (sp *SimpleCommand) pluginsCompleter(args []string) []string {
+	switch len(args) {
+	case 1:
+		return []string{"enable", "disable", "reload"}
+	case 2:
+		return lo.FilterMap(sp.pm.ListPlugins(), func(state pluginabi.PluginState, _ int) (string, bool) {
+			switch args[0] {
+			case "enable":
+				return state.Name, !state.Enabled
+			case "disable":
+				return state.Name, state.Enabled && !state.Core
+			}
+			return state.Name, true
+		})
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/manager"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
+	"github.com/fatih/color"
+	"golang.org/x/exp/maps"
+)
+
+var TeamCollisionRule = []string{"always", "never", "pushOtherTeams", "pushOwnTeam"}
+var TeamNametagVisibility = []string{"always", "never", "hideForOtherTeams", "hideForOwnTeam"}
+var TeamId = regexp.MustCompile(`^[A-Za-z0-9_.+-]+$`)
+var TeamAlreadyExists = regexp.MustCompile(`A team already exists by that name`)
+var TeamUnknown = regexp.MustCompile(`Unknown team`)
+var TeamCommandRejected = regexp.MustCompile(`Unknown or incomplete command|Incorrect argument|Invalid|No entity was found`)
+
+var ErrTeamNotFound = errors.New("team not found")
+
+type TeamCore struct {
+	BasePlugin
+	team map[string]map[string]struct{}
+	lock sync.Mutex
+}
+
+func (tc *TeamCore) Init(pm pluginabi.PluginManager) (err error) {
+	err = tc.BasePlugin.Init(pm, tc)
+	if err != nil {
+		return err
+	}
+	tc.team = make(map[string]map[string]struct{})
+	return nil
+}
+
+// 与记分项相同, 以插件名哈希作为队伍名前缀
+func (tc *TeamCore) teamName(pluginName string, id string) string {
+	return fmt.Sprintf("%s_%s", pluginNamespace(pluginName), id)
+}
+
+// 返回带命名空间的队伍名, 可用于 @a[team=...] 选择器
+func (tc *TeamCore) TeamName(context pluginabi.PluginName, id string) string {
+	return tc.teamName(context.Name(), id)
+}
+
+func (tc *TeamCore) getTeam(context pluginabi.PluginName, id string) (string, error) {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	if _, ok := tc.team[context.Name()][id]; !ok {
+		return "", ErrTeamNotFound
+	}
+	return tc.teamName(context.Name(), id), nil
+}
+
+func (tc *TeamCore) run(command string) error {
+	res := tc.RunCommand(command)
+	switch {
+	case TeamUnknown.MatchString(res):
+		return ErrTeamNotFound
+	case TeamCommandRejected.MatchString(res):
+		return fmt.Errorf("队伍命令执行失败: %s", res)
+	}
+	return nil
+}
+
+func (tc *TeamCore) modify(context pluginabi.PluginName, id string, option string, value string) error {
+	name, err := tc.getTeam(context, id)
+	if err != nil {
+		return err
+	}
+	return tc.run(fmt.Sprintf("team modify %s %s %s", name, option, value))
+}
+
+func (tc *TeamCore) CreateTeam(context pluginabi.PluginName, id string, displayName []tellraw.Message) error {
+	if !TeamId.MatchString(id) {
+		return fmt.Errorf("invalid team id: %s", id)
+	}
+	displayNameMsg, err := tc.tellrawManager.marshal(displayName)
+	if err != nil {
+		return err
+	}
+	name := tc.teamName(context.Name(), id)
+	// 队伍保存在存档中, 已存在时沿用并更新显示名称
+	res := tc.RunCommand(fmt.Sprintf("team add %s %s", name, displayNameMsg))
+	if TeamAlreadyExists.MatchString(res) {
+		err = tc.run(fmt.Sprintf("team modify %s displayName %s", name, displayNameMsg))
+	} else if TeamCommandRejected.MatchString(res) {
+		err = fmt.Errorf("队伍命令执行失败: %s", res)
+	}
+	if err != nil {
+		return err
+	}
+	tc.lock.Lock()
+	if _, ok := tc.team[context.Name()]; !ok {
+		tc.team[context.Name()] = make(map[string]struct{})
+	}
+	tc.team[context.Name()][id] = struct{}{}
+	tc.lock.Unlock()
+	tc.Println(
+		color.YellowString("插件 "),
+		color.BlueString(context.DisplayName()),
+		color.YellowString(" 创建了队伍 "),
+		color.GreenString(id),
+	)
+	return nil
+}
+
+func (tc *TeamCore) SetTeamDisplayName(context pluginabi.PluginName, id string, displayName []tellraw.Message) error {
+	displayNameMsg, err := tc.tellrawManager.marshal(displayName)
+	if err != nil {
+		return err
+	}
+	return tc.modify(context, id, "displayName", displayNameMsg)
+}
+
+// 仅支持 16 种颜色名与 reset
+func (tc *TeamCore) SetTeamColor(context pluginabi.PluginName, id string, teamColor string) error {
+	parsed, err := tellraw.ParseColor(teamColor)
+	if err != nil || strings.HasPrefix(string(parsed), "#") {
+		return fmt.Errorf("invalid team color: %s", teamColor)
+	}
+	return tc.modify(context, id, "color", string(parsed))
+}
+
+func (tc *TeamCore) SetTeamPrefix(context pluginabi.PluginName, id string, prefix []tellraw.Message) error {
+	prefixMsg, err := tc.tellrawManager.marshal(prefix)
+	if err != nil {
+		return err
+	}
+	return tc.modify(context, id, "prefix", prefixMsg)
+}
+
+func (tc *TeamCore) SetTeamSuffix(context pluginabi.PluginName, id string, suffix []tellraw.Message) error {
+	suffixMsg, err := tc.tellrawManager.marshal(suffix)
+	if err != nil {
+		return err
+	}
+	return tc.modify(context, id, "suffix", suffixMsg)
+}
+
+func (tc *TeamCore) SetTeamCollisionRule(context pluginabi.PluginName, id string, rule string) error {
+	if !slices.Contains(TeamCollisionRule, rule) {
+		return fmt.Errorf("invalid collision rule: %s", rule)
+	}
+	return tc.modify(context, id, "collisionRule", rule)
+}
+
+func (tc *TeamCore) SetTeamNametagVisibility(context pluginabi.PluginName, id string, visibility string) error {
+	if !slices.Contains(TeamNametagVisibility, visibility) {
+		return fmt.Errorf("invalid nametag visibility: %s", visibility)
+	}
+	return tc.modify(context, id, "nametagVisibility", visibility)
+}
+
+// members 可为玩家名或选择器, 玩家原有的队伍会被替换
+func (tc *TeamCore) JoinTeam(context pluginabi.PluginName, id string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	name, err := tc.getTeam(context, id)
+	if err != nil {
+		return err
+	}
+	return tc.run(fmt.Sprintf("team join %s %s", name, strings.Join(members, " ")))
+}
+
+func (tc *TeamCore) LeaveTeam(context pluginabi.PluginName, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	return tc.run(fmt.Sprintf("team leave %s", strings.Join(members, " ")))
+}
+
+func (tc *TeamCore) EmptyTeam(context pluginabi.PluginName, id string) error {
+	name, err := tc.getTeam(context, id)
+	if err != nil {
+		return err
+	}
+	return tc.run(fmt.Sprintf("team empty %s", name))
+}
+
+func (tc *TeamCore) RemoveTeam(context pluginabi.PluginName, id string) error {
+	name, err := tc.getTeam(context, id)
+	if err != nil {
+		return err
+	}
+	tc.lock.Lock()
+	delete(tc.team[context.Name()], id)
+	tc.lock.Unlock()
+	return tc.run(fmt.Sprintf("team remove %s", name))
+}
+
+func (tc *TeamCore) RemoveAllTeams(context pluginabi.PluginName) {
+	tc.lock.Lock()
+	ids := maps.Keys(tc.team[context.Name()])
+	delete(tc.team, context.Name())
+	tc.lock.Unlock()
+	for _, id := range ids {
+		tc.RunCommand(fmt.Sprintf("team remove %s", tc.teamName(context.Name(), id)))
+	}
+}
+
+func (tc *TeamCore) Name() string {
+	return "TeamCore"
+}
+
+func (tc *TeamCore) DisplayName() string {
+	return "队伍核心"
+}
+
+func (tc *TeamCore) Start() {
+}
+
+// 与 bossbar 相同, 暂停时移除全部队伍以免残留在存档中
+func (tc *TeamCore) Pause() {
+	tc.lock.Lock()
+	team := tc.team
+	tc.team = make(map[string]map[string]struct{})
+	tc.lock.Unlock()
+	// 服务器已停止时无法执行命令, 队伍随存档保留, 下次创建时沿用
+	if status, err := tc.pm.Status(); err != nil || status.GetState() != manager.MinecraftState_running {
+		return
+	}
+	for plugin, ids := range team {
+		for id := range ids {
+			tc.RunCommand(fmt.Sprintf("team remove %s", tc.teamName(plugin, id)))
+		}
+	}
+}
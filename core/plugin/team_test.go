@@ -0,0 +1,63 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"slices"
+	"testing"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
+)
+
+func newTestTeamCore(t *testing.T) (*TeamCore, *testPluginManager) {
+	t.Helper()
+	pm := newTestPluginManager(t)
+	if _, err := pm.RegisterPlugin(&TellrawManager{}); err != nil {
+		t.Fatal(err)
+	}
+	tc := &TeamCore{}
+	if _, err := pm.RegisterPlugin(tc); err != nil {
+		t.Fatal(err)
+	}
+	return tc, pm
+}
+
+// 队伍名与记分项使用相同的命名空间前缀
+func TestTeamNameNamespace(t *testing.T) {
+	tc, _ := newTestTeamCore(t)
+	sc := &ScoreboardCore{}
+	context := &pluginabi.PluginNameWrapper{PluginName: "PluginA"}
+	if name, objective := tc.TeamName(context, "red"), sc.ObjectiveName(context, "red"); name != objective {
+		t.Errorf("队伍名 %s 与记分项名 %s 的前缀不同", name, objective)
+	}
+}
+
+func TestTeamPause(t *testing.T) {
+	context := &pluginabi.PluginNameWrapper{PluginName: "PluginA"}
+	for _, stopped := range []bool{false, true} {
+		tc, pm := newTestTeamCore(t)
+		if err := tc.CreateTeam(context, "red", []tellraw.Message{{Text: "Red"}}); err != nil {
+			t.Fatal(err)
+		}
+		pm.takeCommands()
+		pm.stopped = stopped
+		tc.Pause()
+		removed := slices.Contains(pm.takeCommands(), "team remove "+tc.TeamName(context, "red"))
+		if removed == stopped {
+			t.Errorf("服务器停止: %v, 移除队伍: %v", stopped, removed)
+		}
+	}
+}
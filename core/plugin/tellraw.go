@@ -17,9 +17,12 @@ package plugin
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
+	"github.com/fatih/color"
 )
 
 type TellrawManager struct {
@@ -39,14 +42,35 @@ func (tm *TellrawManager) Init(pm pluginabi.PluginManager) (err error) {
 	if err != nil {
 		return err
 	}
+	tellraw.SetInvalidColorHandler(func(c tellraw.Color) {
+		tm.Println(color.YellowString("忽略无效的颜色: "), color.RedString(string(c)))
+	})
 	return nil
 }
 
+// 玩家名选择器渲染为昵称, 悬停显示账户名
+func (tm *TellrawManager) renderDisplayName(m *tellraw.Message) {
+	if tm.playerInfo == nil || m.Score != nil || m.Selector == "" || strings.HasPrefix(m.Selector, "@") {
+		return
+	}
+	nick := tm.playerInfo.GetDisplayName(m.Selector)
+	if nick == m.Selector {
+		return
+	}
+	if m.HoverEvent == nil {
+		m.HoverEvent = &tellraw.HoverEvent{Action: tellraw.Show_Text, Contents: []tellraw.Message{{Text: m.Selector, Color: tellraw.Gray}}}
+	}
+	m.Text = nick
+	m.Selector = ""
+	m.Type = tellraw.Text
+}
+
 func (tm *TellrawManager) cleanUp(msg []tellraw.Message) (out []tellraw.Message) {
 	for _, m := range msg {
-		if (m.Type == "" || m.Type == tellraw.Text) && m.Text == "" {
+		if (m.Type == "" || m.Type == tellraw.Text) && !m.HasContent() {
 			continue
 		}
+		tm.renderDisplayName(&m)
 		if m.HoverEvent != nil && m.HoverEvent.Action == tellraw.Show_Text {
 			if m.HoverEvent.Contents == nil {
 				m.HoverEvent = nil
@@ -59,12 +83,13 @@ func (tm *TellrawManager) cleanUp(msg []tellraw.Message) (out []tellraw.Message)
 	return out
 }
 
-func (tm *TellrawManager) clickTriggerWrapper(p pluginabi.PluginName, Selector string, msg []tellraw.Message) []tellraw.Message {
+// players 非空时触发器只对这些玩家启用
+func (tm *TellrawManager) clickTriggerWrapper(p pluginabi.PluginName, Selector string, players []string, msg []tellraw.Message) []tellraw.Message {
 	triggerValueList := []*string{}
 	triggerFuncList := []MinecraftTrigger{}
 	for i := range msg {
 		if msg[i].ClickEvent != nil && msg[i].ClickEvent.Action == tellraw.RunCommand && msg[i].ClickEvent.GoFunc != nil {
-			triggerFuncList = append(triggerFuncList, MinecraftTrigger{Trigger: msg[i].ClickEvent.GoFunc, Time: msg[i].ClickEvent.TriggerTime, Selector: Selector})
+			triggerFuncList = append(triggerFuncList, MinecraftTrigger{Trigger: msg[i].ClickEvent.GoFunc, Time: msg[i].ClickEvent.TriggerTime, Selector: Selector, players: players})
 			triggerValueList = append(triggerValueList, &msg[i].ClickEvent.Value)
 		}
 	}
@@ -77,14 +102,103 @@ func (tm *TellrawManager) clickTriggerWrapper(p pluginabi.PluginName, Selector s
 	return msg
 }
 
-func (tm *TellrawManager) Tellraw(p pluginabi.PluginName, Target string, msg []tellraw.Message) {
+// Selector 为可点击消息触发器的启用范围, players 非空时改为只对列出的玩家启用
+func (tm *TellrawManager) build(p pluginabi.PluginName, Selector string, players []string, msg []tellraw.Message) ([]byte, error) {
 	msg = append([]tellraw.Message{
 		{Text: "[", Color: tellraw.Yellow, Bold: true},
 		{Text: p.DisplayName(), Color: tellraw.Green, Bold: true},
 		{Text: "] ", Color: tellraw.Yellow, Bold: true},
 	}, msg...)
 	msg = tm.cleanUp(msg)
-	msg = tm.clickTriggerWrapper(p, Target, msg)
-	jsonMsg, _ := json.Marshal(msg)
+	msg = tm.clickTriggerWrapper(p, Selector, players, msg)
+	jsonMsg, err := json.Marshal(msg)
+	if err != nil {
+		tm.Println(color.RedString("序列化 tellraw 消息失败: "), color.MagentaString(err.Error()))
+		return nil, err
+	}
+	return jsonMsg, nil
+}
+
+func (tm *TellrawManager) Tellraw(p pluginabi.PluginName, Target string, msg []tellraw.Message) error {
+	jsonMsg, err := tm.build(p, Target, nil, msg)
+	if err != nil {
+		return err
+	}
 	tm.RunCommand(fmt.Sprintf("tellraw %s %s", Target, jsonMsg))
+	return nil
+}
+
+// 批量发送时每次提交的命令总长度上限, 单条命令超过时单独提交
+const TellrawMaxBatchLength = 4096
+
+// 向 players 发送同一消息, 只对列出的玩家逐个执行 tellraw, 按 TellrawMaxBatchLength 合并为多行命令提交
+func (tm *TellrawManager) TellrawMulti(p pluginabi.PluginName, players []string, msg []tellraw.Message) error {
+	if len(players) == 0 {
+		return nil
+	}
+	// 可点击消息的触发器只对收件人启用
+	jsonMsg, err := tm.build(p, "", players, msg)
+	if err != nil {
+		return err
+	}
+	batch := []string{}
+	length := 0
+	for _, player := range players {
+		line := fmt.Sprintf("tellraw %s %s", player, jsonMsg)
+		if len(batch) > 0 && length+len(line)+1 > TellrawMaxBatchLength {
+			tm.RunCommand(strings.Join(batch, "\n"))
+			batch, length = batch[:0], 0
+		}
+		batch = append(batch, line)
+		length += len(line) + 1
+	}
+	tm.RunCommand(strings.Join(batch, "\n"))
+	return nil
+}
+
+var TellrawSelector = regexp.MustCompile(`^@[aprse](?:\[.*\])?$`)
+
+// 原版玩家名, 允许 Floodgate 基岩版玩家的 . 前缀
+var PlayerNamePattern = regexp.MustCompile(`^\.?\w{1,16}$`)
+
+func (tm *TellrawManager) marshal(msg []tellraw.Message) (string, error) {
+	msg = tm.cleanUp(msg)
+	if len(msg) == 0 {
+		msg = []tellraw.Message{{Text: ""}}
+	}
+	jsonMsg, err := json.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonMsg), nil
+}
+
+func (tm *TellrawManager) Title(Target string, title []tellraw.Message, subtitle []tellraw.Message, fadeIn int, stay int, fadeOut int) error {
+	if fadeIn < 0 || stay < 0 || fadeOut < 0 {
+		return fmt.Errorf("invalid title times: %d %d %d", fadeIn, stay, fadeOut)
+	}
+	titleMsg, err := tm.marshal(title)
+	if err != nil {
+		return err
+	}
+	tm.RunCommand(fmt.Sprintf("title %s times %d %d %d", Target, fadeIn, stay, fadeOut))
+	// 副标题需在标题之前设置, 显示标题时一并显示
+	if len(subtitle) > 0 {
+		subtitleMsg, err := tm.marshal(subtitle)
+		if err != nil {
+			return err
+		}
+		tm.RunCommand(fmt.Sprintf("title %s subtitle %s", Target, subtitleMsg))
+	}
+	tm.RunCommand(fmt.Sprintf("title %s title %s", Target, titleMsg))
+	return nil
+}
+
+func (tm *TellrawManager) ActionBar(Target string, msg []tellraw.Message) error {
+	actionbarMsg, err := tm.marshal(msg)
+	if err != nil {
+		return err
+	}
+	tm.RunCommand(fmt.Sprintf("title %s actionbar %s", Target, actionbarMsg))
+	return nil
 }
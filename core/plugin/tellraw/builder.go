@@ -0,0 +1,108 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tellraw
+
+// 链式构建消息, 样式修饰作用于最后添加的片段
+type Builder struct {
+	msg []Message
+}
+
+func New() *Builder {
+	return &Builder{}
+}
+
+func (b *Builder) last() *Message {
+	if len(b.msg) == 0 {
+		return nil
+	}
+	return &b.msg[len(b.msg)-1]
+}
+
+func (b *Builder) modify(f func(m *Message)) *Builder {
+	if m := b.last(); m != nil {
+		f(m)
+	}
+	return b
+}
+
+func (b *Builder) Append(msg ...Message) *Builder {
+	b.msg = append(b.msg, msg...)
+	return b
+}
+
+func (b *Builder) Text(text string) *Builder {
+	return b.Append(Message{Text: text})
+}
+
+func (b *Builder) Translate(key string, args ...Message) *Builder {
+	return b.Append(TranslatableMessage(key, args...))
+}
+
+func (b *Builder) Selector(selector string) *Builder {
+	return b.Append(Message{Selector: selector})
+}
+
+func (b *Builder) Score(name string, objective string) *Builder {
+	return b.Append(Message{Score: &ScoreComponent{Name: name, Objective: objective}})
+}
+
+func (b *Builder) Color(color Color) *Builder {
+	return b.modify(func(m *Message) { m.Color = color })
+}
+
+func (b *Builder) Bold() *Builder {
+	return b.modify(func(m *Message) { m.Bold = true })
+}
+
+func (b *Builder) Italic() *Builder {
+	return b.modify(func(m *Message) { m.Italic = true })
+}
+
+func (b *Builder) Underlined() *Builder {
+	return b.modify(func(m *Message) { m.Underlined = true })
+}
+
+func (b *Builder) Strikethrough() *Builder {
+	return b.modify(func(m *Message) { m.Strikethrough = true })
+}
+
+func (b *Builder) Obfuscated() *Builder {
+	return b.modify(func(m *Message) { m.Obfuscated = true })
+}
+
+func (b *Builder) Insertion(insertion string) *Builder {
+	return b.modify(func(m *Message) { m.Insertion = insertion })
+}
+
+func (b *Builder) Hover(msg ...Message) *Builder {
+	return b.modify(func(m *Message) { m.HoverEvent = &HoverEvent{Action: Show_Text, Contents: msg} })
+}
+
+func (b *Builder) Click(action ClickEvent_Action, value string) *Builder {
+	return b.modify(func(m *Message) { m.ClickEvent = &ClickEvent{Action: action, Value: value} })
+}
+
+// 点击时回调 Go 函数, 由 TellrawManager 注册为 trigger
+func (b *Builder) OnClick(f GoFunc, triggerTime int64) *Builder {
+	return b.modify(func(m *Message) {
+		m.ClickEvent = &ClickEvent{Action: RunCommand, GoFunc: f, TriggerTime: triggerTime}
+	})
+}
+
+func (b *Builder) Build() []Message {
+	out := make([]Message, len(b.msg))
+	copy(out, b.msg)
+	return out
+}
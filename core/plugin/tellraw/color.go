@@ -0,0 +1,144 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tellraw
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+func isHexColor(s string) bool {
+	if len(s) != 7 || s[0] != '#' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// 1.16+ 支持 #RRGGBB 格式的颜色
+func HexColor(hex string) (Color, error) {
+	if !strings.HasPrefix(hex, "#") {
+		hex = "#" + hex
+	}
+	if !isHexColor(hex) {
+		return "", fmt.Errorf("invalid hex color: %s", hex)
+	}
+	return Color(strings.ToUpper(hex)), nil
+}
+
+// 有效颜色为 16 种颜色名 (不区分大小写), reset 与 #RRGGBB
+func ParseColor(s string) (Color, error) {
+	name := Color(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := namedColorHex[name]; ok || name == Reset {
+		return name, nil
+	}
+	if strings.HasPrefix(string(name), "#") {
+		return HexColor(string(name))
+	}
+	return "", fmt.Errorf("invalid color: %s", s)
+}
+
+var invalidColorHandler atomic.Pointer[func(Color)]
+
+// 序列化时遇到无效颜色的回调, 无效颜色本身会被忽略
+func SetInvalidColorHandler(handler func(c Color)) {
+	invalidColorHandler.Store(&handler)
+}
+
+func reportInvalidColor(c Color) {
+	if handler := invalidColorHandler.Load(); handler != nil && *handler != nil {
+		(*handler)(c)
+	}
+}
+
+// 无效颜色序列化为空字符串, 不返回错误
+func (c Color) MarshalJSON() ([]byte, error) {
+	if c == "" {
+		return json.Marshal("")
+	}
+	color, err := ParseColor(string(c))
+	if err != nil {
+		reportInvalidColor(c)
+	}
+	return json.Marshal(string(color))
+}
+
+var namedColorHex = map[Color]string{
+	Black:        "#000000",
+	Dark_Blue:    "#0000AA",
+	Dark_Green:   "#00AA00",
+	Dark_Aqua:    "#00AAAA",
+	Dark_Red:     "#AA0000",
+	Dark_Purple:  "#AA00AA",
+	Bold:         "#FFAA00",
+	Gray:         "#AAAAAA",
+	Dark_Gray:    "#555555",
+	Blue:         "#5555FF",
+	Green:        "#55FF55",
+	Aqua:         "#55FFFF",
+	Red:          "#FF5555",
+	Light_Purple: "#FF55FF",
+	Yellow:       "#FFFF55",
+	White:        "#FFFFFF",
+}
+
+func (c Color) rgb() (r, g, b uint8, ok bool) {
+	hex := string(c)
+	if named, found := namedColorHex[c]; found {
+		hex = named
+	}
+	if !isHexColor(hex) {
+		return 0, 0, 0, false
+	}
+	var v uint32
+	_, err := fmt.Sscanf(hex[1:], "%06x", &v)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), true
+}
+
+// 渐变文本的最大组件数, 超出时多个字符共用一个颜色
+const GradientMaxComponents = 128
+
+func Gradient(text string, from Color, to Color) []Message {
+	runes := []rune(text)
+	fr, fg, fb, okFrom := from.rgb()
+	tr, tg, tb, okTo := to.rgb()
+	if !okFrom || !okTo || len(runes) < 2 {
+		return []Message{{Text: text, Color: from}}
+	}
+	step := (len(runes) + GradientMaxComponents - 1) / GradientMaxComponents
+	segments := (len(runes) + step - 1) / step
+	out := make([]Message, 0, segments)
+	lerp := func(a, b uint8, t float64) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*t + 0.5)
+	}
+	for i := 0; i < segments; i++ {
+		t := 0.0
+		if segments > 1 {
+			t = float64(i) / float64(segments-1)
+		}
+		color := Color(fmt.Sprintf("#%02X%02X%02X", lerp(fr, tr, t), lerp(fg, tg, t), lerp(fb, tb, t)))
+		out = append(out, Message{Text: string(runes[i*step : min((i+1)*step, len(runes))]), Color: color})
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tellraw
+
+import (
+	"strings"
+)
+
+var legacyColor = map[rune]Color{
+	'0': Black,
+	'1': Dark_Blue,
+	'2': Dark_Green,
+	'3': Dark_Aqua,
+	'4': Dark_Red,
+	'5': Dark_Purple,
+	'6': Bold, // gold
+	'7': Gray,
+	'8': Dark_Gray,
+	'9': Blue,
+	'a': Green,
+	'b': Aqua,
+	'c': Red,
+	'd': Light_Purple,
+	'e': Yellow,
+	'f': White,
+}
+
+func isLegacyPrefix(r rune) bool {
+	return r == '§' || r == '&'
+}
+
+// 将 §a/&l 等旧式格式代码转换为 tellraw 消息, 无法识别的代码按原文保留
+func FromLegacy(s string) []Message {
+	out := []Message{}
+	style := Message{}
+	var text strings.Builder
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		msg := style
+		msg.Text = text.String()
+		out = append(out, msg)
+		text.Reset()
+	}
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if !isLegacyPrefix(runes[i]) || i+1 >= len(runes) {
+			text.WriteRune(runes[i])
+			continue
+		}
+		code := []rune(strings.ToLower(string(runes[i+1])))[0]
+		if color, ok := legacyColor[code]; ok {
+			flush()
+			style = Message{Color: color}
+			i++
+			continue
+		}
+		switch code {
+		case 'x':
+			// Spigot 十六进制颜色: §x§R§R§G§G§B§B
+			if hex, ok := legacyHexColor(runes[i+2:]); ok {
+				flush()
+				style = Message{Color: hex}
+				i += 13
+				continue
+			}
+		case 'k', 'l', 'm', 'n', 'o', 'r':
+			flush()
+			switch code {
+			case 'k':
+				style.Obfuscated = true
+			case 'l':
+				style.Bold = true
+			case 'm':
+				style.Strikethrough = true
+			case 'n':
+				style.Underlined = true
+			case 'o':
+				style.Italic = true
+			case 'r':
+				style = Message{}
+			}
+			i++
+			continue
+		}
+		text.WriteRune(runes[i])
+	}
+	flush()
+	return out
+}
+
+func legacyHexColor(runes []rune) (Color, bool) {
+	if len(runes) < 12 {
+		return "", false
+	}
+	hex := []rune{'#'}
+	for i := 0; i < 12; i += 2 {
+		if !isLegacyPrefix(runes[i]) {
+			return "", false
+		}
+		hex = append(hex, runes[i+1])
+	}
+	color, err := HexColor(string(hex))
+	if err != nil {
+		return "", false
+	}
+	return color, true
+}
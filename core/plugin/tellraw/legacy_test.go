@@ -0,0 +1,40 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tellraw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFromLegacy(t *testing.T) {
+	cases := []struct {
+		legacy string
+		msg    []Message
+	}{
+		{"§a§lHello §rWorld", []Message{{Text: "Hello ", Color: Green, Bold: true}, {Text: "World"}}},
+		{"§lBold§cRed", []Message{{Text: "Bold", Bold: true}, {Text: "Red", Color: Red}}},
+		{"&6Gold&o&nStyled", []Message{{Text: "Gold", Color: Bold}, {Text: "Styled", Color: Bold, Italic: true, Underlined: true}}},
+		{"§9§m§kHidden", []Message{{Text: "Hidden", Color: Blue, Strikethrough: true, Obfuscated: true}}},
+		{"§AUpper§BCase", []Message{{Text: "Upper", Color: Green}, {Text: "Case", Color: Aqua}}},
+		{"§x§f§f§0§0§0§0Hex§lBold", []Message{{Text: "Hex", Color: "#FF0000"}, {Text: "Bold", Color: "#FF0000", Bold: true}}},
+		{"§zUnknown&", []Message{{Text: "§zUnknown&"}}},
+	}
+	for _, c := range cases {
+		if msg := FromLegacy(c.legacy); !reflect.DeepEqual(msg, c.msg) {
+			t.Errorf("FromLegacy(%q) = %+v, 应为 %+v", c.legacy, msg, c.msg)
+		}
+	}
+}
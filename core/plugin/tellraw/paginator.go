@@ -0,0 +1,70 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tellraw
+
+import "fmt"
+
+// 分页输出, 每个元素为一行消息
+// 设置 Command 时翻页按钮执行 "<Command> <页码>", 否则调用 OnPage
+type Paginator struct {
+	Lines    [][]Message
+	PageSize int
+	Command  string
+	OnPage   func(player string, page int)
+}
+
+func NewPaginator(lines [][]Message, pageSize int) *Paginator {
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	return &Paginator{Lines: lines, PageSize: pageSize}
+}
+
+func (p *Paginator) Pages() int {
+	return max((len(p.Lines)+p.PageSize-1)/p.PageSize, 1)
+}
+
+func (p *Paginator) pageButton(text string, page int) Message {
+	button := Message{Text: text, Color: Aqua, HoverEvent: &HoverEvent{Action: Show_Text, Contents: []Message{{Text: fmt.Sprintf("第 %d 页", page), Color: Yellow}}}}
+	if p.Command != "" {
+		button.ClickEvent = &ClickEvent{Action: RunCommand, Value: fmt.Sprintf("%s %d", p.Command, page)}
+	} else if p.OnPage != nil {
+		button.ClickEvent = &ClickEvent{Action: RunCommand, GoFunc: func(player string, _ int) { p.OnPage(player, page) }}
+	}
+	return button
+}
+
+// 页码从 1 开始, 超出范围时取最近的有效页
+func (p *Paginator) RenderPage(page int) [][]Message {
+	pages := p.Pages()
+	page = min(max(page, 1), pages)
+	start := (page - 1) * p.PageSize
+	end := min(start+p.PageSize, len(p.Lines))
+	out := make([][]Message, 0, end-start+1)
+	out = append(out, p.Lines[start:end]...)
+	footer := []Message{}
+	if page > 1 {
+		footer = append(footer, p.pageButton("<< 上一页", page-1))
+	} else {
+		footer = append(footer, Message{Text: "<< 上一页", Color: Dark_Gray})
+	}
+	footer = append(footer, Message{Text: fmt.Sprintf(" %d/%d ", page, pages), Color: Yellow})
+	if page < pages {
+		footer = append(footer, p.pageButton("下一页 >>", page+1))
+	} else {
+		footer = append(footer, Message{Text: "下一页 >>", Color: Dark_Gray})
+	}
+	return append(out, footer)
+}
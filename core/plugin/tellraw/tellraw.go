@@ -14,6 +14,11 @@
 
 package tellraw
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 type Color string
 
 type MsgType string
@@ -35,6 +40,7 @@ var (
 	Light_Purple Color = "light_purple"
 	Yellow       Color = "yellow"
 	White        Color = "white"
+	Reset        Color = "reset"
 )
 
 var (
@@ -47,20 +53,71 @@ var (
 )
 
 type Message struct {
-	Text          string      `json:"text,omitempty"`
-	Color         Color       `json:"color,omitempty"`
-	Type          MsgType     `json:"type,omitempty"`
-	Insertion     string      `json:"insertion,omitempty"`
-	Font          string      `json:"font,omitempty"`
-	Selector      string      `json:"selector,omitempty"`
-	Separator     *Message    `json:"separator,omitempty"`
-	Bold          bool        `json:"bold,omitempty"`
-	Italic        bool        `json:"italic,omitempty"`
-	Underlined    bool        `json:"underlined,omitempty"`
-	Strikethrough bool        `json:"strikethrough,omitempty"`
-	Obfuscated    bool        `json:"obfuscated,omitempty"`
-	HoverEvent    *HoverEvent `json:"hoverEvent,omitempty"`
-	ClickEvent    *ClickEvent `json:"clickEvent,omitempty"`
+	Text          string          `json:"text,omitempty"`
+	Color         Color           `json:"color,omitempty"`
+	Type          MsgType         `json:"type,omitempty"`
+	Insertion     string          `json:"insertion,omitempty"`
+	Font          string          `json:"font,omitempty"`
+	Translate     string          `json:"translate,omitempty"`
+	With          []Message       `json:"with,omitempty"`
+	Score         *ScoreComponent `json:"score,omitempty"`
+	Selector      string          `json:"selector,omitempty"`
+	Separator     *Message        `json:"separator,omitempty"`
+	Bold          bool            `json:"bold,omitempty"`
+	Italic        bool            `json:"italic,omitempty"`
+	Underlined    bool            `json:"underlined,omitempty"`
+	Strikethrough bool            `json:"strikethrough,omitempty"`
+	Obfuscated    bool            `json:"obfuscated,omitempty"`
+	HoverEvent    *HoverEvent     `json:"hoverEvent,omitempty"`
+	ClickEvent    *ClickEvent     `json:"clickEvent,omitempty"`
+}
+
+type ScoreComponent struct {
+	Name      string `json:"name"`
+	Objective string `json:"objective"`
+}
+
+// 内容字段互斥, 优先级为 Score > Selector > Translate > Text, 序列化时只保留优先级最高的一个
+// 同时设置 Translate 与 Text 视为错误
+func (m Message) MarshalJSON() ([]byte, error) {
+	type message Message
+	msg := message(m)
+	if msg.Translate != "" && msg.Text != "" {
+		return nil, fmt.Errorf("translate and text are both set: %s", msg.Translate)
+	}
+	// 无效的颜色不输出, 不影响整条消息
+	if msg.Color != "" {
+		c, err := ParseColor(string(msg.Color))
+		if err != nil {
+			reportInvalidColor(msg.Color)
+		}
+		msg.Color = c
+	}
+	switch {
+	case msg.Score != nil:
+		msg.Type = Score
+		msg.Text = ""
+		msg.Selector = ""
+	case msg.Selector != "":
+		msg.Type = Selector
+		msg.Text = ""
+	case msg.Translate != "":
+		msg.Type = Translatable
+	}
+	if msg.Type == Score || msg.Type == Selector {
+		msg.Translate = ""
+		msg.With = nil
+	}
+	return json.Marshal(msg)
+}
+
+func (m *Message) HasContent() bool {
+	return m.Text != "" || m.Translate != "" || m.Score != nil || m.Selector != ""
+}
+
+// Translatable 已用作 MsgType, 故命名为 TranslatableMessage
+func TranslatableMessage(key string, args ...Message) Message {
+	return Message{Translate: key, With: args}
 }
 
 type HoverEvent_Action string
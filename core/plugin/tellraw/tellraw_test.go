@@ -0,0 +1,86 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tellraw
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalStyledMessage(t *testing.T) {
+	cases := []struct {
+		msg      Message
+		expected string
+	}{
+		{
+			Message{Text: "Hi", Color: Red, Bold: true, Italic: true, Underlined: true, Strikethrough: true, Obfuscated: true},
+			`{"text":"Hi","color":"red","bold":true,"italic":true,"underlined":true,"strikethrough":true,"obfuscated":true}`,
+		},
+		{
+			Message{Text: "Plain"},
+			`{"text":"Plain"}`,
+		},
+		{
+			Message{Text: "ignored", Selector: "@p", Italic: true},
+			`{"type":"selector","selector":"@p","italic":true}`,
+		},
+		{
+			Message{Translate: "chat.type.text", With: []Message{{Text: "Steve", Underlined: true}}, Color: Gray},
+			`{"color":"gray","type":"translatable","translate":"chat.type.text","with":[{"text":"Steve","underlined":true}]}`,
+		},
+		{
+			Message{Text: "Hex", Color: "#ff00aa"},
+			`{"text":"Hex","color":"#FF00AA"}`,
+		},
+		{
+			Message{Text: "Reset", Color: "RESET"},
+			`{"text":"Reset","color":"reset"}`,
+		},
+		{
+			Message{Text: "Invalid", Color: "rainbow", With: []Message{{Text: "nested", Color: "#12345"}}},
+			`{"text":"Invalid","with":[{"text":"nested"}]}`,
+		},
+	}
+	for _, c := range cases {
+		data, err := json.Marshal(c.msg)
+		if err != nil {
+			t.Errorf("序列化 %+v 失败: %v", c.msg, err)
+			continue
+		}
+		if string(data) != c.expected {
+			t.Errorf("序列化结果为 %s, 应为 %s", data, c.expected)
+		}
+	}
+	if _, err := json.Marshal(Message{Text: "a", Translate: "b"}); err == nil {
+		t.Error("同时设置 Text 与 Translate 时应返回错误")
+	}
+}
+
+// 无效颜色被忽略并上报, 不影响序列化
+func TestMarshalInvalidColor(t *testing.T) {
+	reported := []Color{}
+	SetInvalidColorHandler(func(c Color) { reported = append(reported, c) })
+	defer SetInvalidColorHandler(nil)
+	data, err := json.Marshal([]Message{{Text: "a", Color: "rainbow"}, {Text: "b", Color: Green}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `[{"text":"a"},{"text":"b","color":"green"}]`; string(data) != expected {
+		t.Errorf("序列化结果为 %s, 应为 %s", data, expected)
+	}
+	if len(reported) != 1 || reported[0] != "rainbow" {
+		t.Errorf("上报的颜色为 %q", reported)
+	}
+}
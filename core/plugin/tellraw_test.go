@@ -0,0 +1,124 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
+)
+
+func TestTellrawRenderDisplayName(t *testing.T) {
+	pi, pm := newTestPlayerInfo(t, "Steve", "Alex")
+	if err := pi.SetDisplayName("Steve", "Nick"); err != nil {
+		t.Fatal(err)
+	}
+	tm := &TellrawManager{}
+	if _, err := pm.RegisterPlugin(tm); err != nil {
+		t.Fatal(err)
+	}
+	out := tm.cleanUp([]tellraw.Message{
+		{Type: tellraw.Selector, Selector: "Steve"},
+		{Type: tellraw.Selector, Selector: "Alex"},
+		{Type: tellraw.Selector, Selector: "@a"},
+	})
+	if out[0].Text != "Nick" || out[0].Selector != "" || out[0].HoverEvent == nil {
+		t.Errorf("昵称未渲染: %+v", out[0])
+	}
+	if out[1].Selector != "Alex" || out[2].Selector != "@a" {
+		t.Errorf("无昵称的选择器被修改: %+v %+v", out[1], out[2])
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	tp, _ := newTestTellrawPlugin(t)
+	valid := map[string]string{"@a": "@a", "@p[distance=..5]": "@p[distance=..5]", "Steve": "Steve", "Nick": "Steve"}
+	for target, expected := range valid {
+		if resolved, err := tp.resolveTarget(target); err != nil || resolved != expected {
+			t.Errorf("resolveTarget(%q) = %q, %v, 应为 %q", target, resolved, err, expected)
+		}
+	}
+	for _, target := range []string{"@x", "Alex", "Steve run say hi", "Steve\nop Alex", ""} {
+		if resolved, err := tp.resolveTarget(target); err == nil {
+			t.Errorf("resolveTarget(%q) = %q, 应返回错误", target, resolved)
+		}
+	}
+}
+
+type testPlugin struct {
+	BasePlugin
+}
+
+func (tp *testPlugin) Name() string {
+	return "TestPlugin"
+}
+
+func (tp *testPlugin) Init(pm pluginabi.PluginManager) error {
+	return tp.BasePlugin.Init(pm, tp)
+}
+
+func newTestTellrawPlugin(t *testing.T) (*testPlugin, *testPluginManager) {
+	pi, pm := newTestPlayerInfo(t, "Steve", "Alex")
+	if err := pi.SetDisplayName("Steve", "Nick"); err != nil {
+		t.Fatal(err)
+	}
+	pi.playerListLock.Lock()
+	pi.playerList = []string{"Steve"}
+	pi.playerListLock.Unlock()
+	if _, err := pm.RegisterPlugin(&TellrawManager{}); err != nil {
+		t.Fatal(err)
+	}
+	tp := &testPlugin{}
+	if _, err := pm.RegisterPlugin(tp); err != nil {
+		t.Fatal(err)
+	}
+	pm.takeCommands()
+	return tp, pm
+}
+
+func TestActionBarTarget(t *testing.T) {
+	tp, pm := newTestTellrawPlugin(t)
+	msg := []tellraw.Message{{Text: "hi"}}
+	if err := tp.ActionBar("Steve run say hi", msg); err == nil {
+		t.Error("非法目标应返回错误")
+	}
+	if err := tp.Title("@x", msg, nil, 0, 20, 0); err == nil {
+		t.Error("非法选择器应返回错误")
+	}
+	if commands := pm.takeCommands(); len(commands) != 0 {
+		t.Errorf("非法目标不应发送命令: %q", commands)
+	}
+	if err := tp.ActionBar("Nick", msg); err != nil {
+		t.Fatal(err)
+	}
+	commands := pm.takeCommands()
+	if len(commands) != 1 || !strings.HasPrefix(commands[0], "title Steve actionbar ") {
+		t.Errorf("commands = %q", commands)
+	}
+}
+
+// 选择器, 不在线和非法的玩家名不会收到消息
+func TestTellrawMultiTargets(t *testing.T) {
+	tp, pm := newTestTellrawPlugin(t)
+	if err := tp.TellrawMulti([]string{"Nick", "Steve", "Alex", "@a", "Steve run say hi"}, []tellraw.Message{{Text: "hi"}}); err != nil {
+		t.Fatal(err)
+	}
+	commands := strings.Split(strings.Join(pm.takeCommands(), "\n"), "\n")
+	if len(commands) != 1 || !strings.HasPrefix(commands[0], "tellraw Steve ") {
+		t.Errorf("commands = %q", commands)
+	}
+}
@@ -14,9 +14,49 @@
 
 package plugin
 
-var worldName = map[string]string{
-	"minecraft:overworld":  "主世界",
-	"minecraft:the_end":    "末地",
-	"minecraft:the_nether": "地狱",
-	"Overall":              "服务器",
+import (
+	"sync"
+
+	"golang.org/x/exp/maps"
+)
+
+type worldNameRegistry struct {
+	names     map[string]string // 内置及插件注册的名称
+	overrides map[string]string // 配置文件中的名称, 优先于插件注册
+	lock      sync.RWMutex
+}
+
+var worldNames = &worldNameRegistry{
+	names: map[string]string{
+		"minecraft:overworld":  "主世界",
+		"minecraft:the_end":    "末地",
+		"minecraft:the_nether": "地狱",
+		"Overall":              "服务器",
+	},
+	overrides: map[string]string{},
+}
+
+func (r *worldNameRegistry) register(id string, name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.names[id] = name
+}
+
+func (r *worldNameRegistry) override(names map[string]string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	maps.Copy(r.overrides, names)
+}
+
+// 未登记的维度返回原始 id
+func (r *worldNameRegistry) get(id string) string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if name, ok := r.overrides[id]; ok {
+		return name
+	}
+	if name, ok := r.names[id]; ok {
+		return name
+	}
+	return id
 }
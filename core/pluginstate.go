@@ -0,0 +1,136 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/manager"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"github.com/fatih/color"
+)
+
+// 返回 false 表示插件已被禁用
+func (pm *PluginManager) Disable() bool {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+	if pm.disabled {
+		return false
+	}
+	pm.disabled = true
+	pm.pause()
+	return true
+}
+
+// 返回 false 表示插件未被禁用, start 为 true 时立即启动
+func (pm *PluginManager) Enable(start bool) bool {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+	if !pm.disabled {
+		return false
+	}
+	pm.disabled = false
+	if start {
+		pm.start()
+	}
+	return true
+}
+
+func (pm *PluginManager) State() pluginabi.PluginState {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+	return pluginabi.PluginState{
+		Name:        pm.plugin.Name(),
+		DisplayName: pm.plugin.DisplayName(),
+		Enabled:     !pm.disabled,
+		Started:     pm.started,
+		Core:        pm.core,
+	}
+}
+
+func (mpm *MinecraftPluginManager) getPluginManager(pluginName string) (*PluginManager, error) {
+	mpm.pluginLock.RLock()
+	defer mpm.pluginLock.RUnlock()
+	pm, ok := mpm.plugins[pluginName]
+	if !ok {
+		return nil, fmt.Errorf("插件 %s 不存在", pluginName)
+	}
+	return pm, nil
+}
+
+// 暂停插件且服务器重启后不再启动, 内置插件不可禁用
+func (mpm *MinecraftPluginManager) DisablePlugin(pluginName string) error {
+	pm, err := mpm.getPluginManager(pluginName)
+	if err != nil {
+		return err
+	}
+	if pm.core {
+		return fmt.Errorf("内置插件 %s 不可禁用", pluginName)
+	}
+	if !pm.Disable() {
+		return fmt.Errorf("插件 %s 已被禁用", pluginName)
+	}
+	mpm.kPrintln(color.YellowString("插件 "), color.BlueString(pm.plugin.DisplayName()), color.YellowString(" 已禁用"))
+	return nil
+}
+
+func (mpm *MinecraftPluginManager) EnablePlugin(pluginName string) error {
+	pm, err := mpm.getPluginManager(pluginName)
+	if err != nil {
+		return err
+	}
+	if !pm.Enable(mpm.minecraftState == manager.MinecraftState_running) {
+		return fmt.Errorf("插件 %s 未被禁用", pluginName)
+	}
+	mpm.kPrintln(color.YellowString("插件 "), color.BlueString(pm.plugin.DisplayName()), color.GreenString(" 已启用"))
+	return nil
+}
+
+func (mpm *MinecraftPluginManager) ReloadPluginConfig(pluginName string) error {
+	pm, err := mpm.getPluginManager(pluginName)
+	if err != nil {
+		return err
+	}
+	reloader, ok := pm.plugin.(pluginabi.ConfigReloader)
+	if !ok {
+		return fmt.Errorf("插件 %s 不支持重新加载配置", pluginName)
+	}
+	err = reloader.ReloadConfig()
+	if err != nil {
+		return err
+	}
+	mpm.kPrintln(color.YellowString("插件 "), color.BlueString(pm.plugin.DisplayName()), color.GreenString(" 配置已重新加载"))
+	return nil
+}
+
+// 未注册的插件视为未禁用
+func (mpm *MinecraftPluginManager) PluginDisabled(pluginName string) bool {
+	pm, err := mpm.getPluginManager(pluginName)
+	if err != nil {
+		return false
+	}
+	return !pm.State().Enabled
+}
+
+// 按初始化顺序返回
+func (mpm *MinecraftPluginManager) ListPlugins() []pluginabi.PluginState {
+	mpm.pluginLock.RLock()
+	defer mpm.pluginLock.RUnlock()
+	states := make([]pluginabi.PluginState, 0, len(mpm.initOrder))
+	for _, pm := range mpm.initOrder {
+		states = append(states, pm.State())
+	}
+	return states
+}
@@ -0,0 +1,194 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rcon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	packetResponse int32 = 0
+	packetCommand  int32 = 2
+	packetAuth     int32 = 3
+
+	// 服务端单个请求包的最大长度
+	maxRequestPayload = 1446
+	maxPacketSize     = 4096 + 14
+)
+
+type Client struct {
+	Address  string
+	Password string
+	Timeout  time.Duration
+	conn     net.Conn
+	reader   *bufio.Reader
+	id       int32
+	lock     sync.Mutex
+}
+
+func Dial(address string, password string) (*Client, error) {
+	c := &Client{Address: address, Password: password, Timeout: 10 * time.Second}
+	err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) connect() (err error) {
+	conn, err := net.DialTimeout("tcp", c.Address, c.Timeout)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+	// 认证失败时不保留已关闭的连接, 下次调用时重连
+	defer func() {
+		if err != nil {
+			conn.Close()
+			c.conn = nil
+			c.reader = nil
+		}
+	}()
+	id := c.nextId()
+	err = c.writePacket(id, packetAuth, c.Password)
+	if err != nil {
+		return err
+	}
+	for {
+		respId, respType, _, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+		// 部分服务端会先回复一个空的 RESPONSE_VALUE
+		if respType != packetCommand {
+			continue
+		}
+		if respId == -1 {
+			return fmt.Errorf("rcon 认证失败")
+		}
+		return nil
+	}
+}
+
+func (c *Client) nextId() int32 {
+	c.id++
+	if c.id <= 0 {
+		c.id = 1
+	}
+	return c.id
+}
+
+func (c *Client) writePacket(id int32, packetType int32, payload string) error {
+	buf := bytes.NewBuffer(make([]byte, 0, len(payload)+14))
+	binary.Write(buf, binary.LittleEndian, int32(len(payload)+10))
+	binary.Write(buf, binary.LittleEndian, id)
+	binary.Write(buf, binary.LittleEndian, packetType)
+	buf.WriteString(payload)
+	buf.Write([]byte{0, 0})
+	c.conn.SetWriteDeadline(time.Now().Add(c.Timeout))
+	_, err := c.conn.Write(buf.Bytes())
+	return err
+}
+
+func (c *Client) readPacket() (id int32, packetType int32, payload string, err error) {
+	c.conn.SetReadDeadline(time.Now().Add(c.Timeout))
+	var size int32
+	err = binary.Read(c.reader, binary.LittleEndian, &size)
+	if err != nil {
+		return
+	}
+	if size < 10 || size > maxPacketSize {
+		err = fmt.Errorf("invalid rcon packet size %d", size)
+		return
+	}
+	data := make([]byte, size)
+	_, err = io.ReadFull(c.reader, data)
+	if err != nil {
+		return
+	}
+	id = int32(binary.LittleEndian.Uint32(data[0:4]))
+	packetType = int32(binary.LittleEndian.Uint32(data[4:8]))
+	payload = string(bytes.TrimRight(data[8:], "\x00"))
+	return
+}
+
+// 执行命令并返回完整输出
+// 长输出会被拆成多个包, 命令后紧跟一个哨兵包, 收到哨兵的回复即代表命令输出已接收完毕
+func (c *Client) Command(command string) (string, error) {
+	if len(command) > maxRequestPayload {
+		return "", fmt.Errorf("command too long for rcon: %d bytes", len(command))
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.conn == nil {
+		err := c.connect()
+		if err != nil {
+			return "", err
+		}
+	}
+	response, err := c.command(command)
+	if err != nil {
+		// 连接出错后丢弃, 下次调用时重连
+		c.conn.Close()
+		c.conn = nil
+	}
+	return response, err
+}
+
+func (c *Client) command(command string) (string, error) {
+	id := c.nextId()
+	sentinel := c.nextId()
+	err := c.writePacket(id, packetCommand, command)
+	if err != nil {
+		return "", err
+	}
+	err = c.writePacket(sentinel, packetResponse, "")
+	if err != nil {
+		return "", err
+	}
+	var response strings.Builder
+	for {
+		respId, _, payload, err := c.readPacket()
+		if err != nil {
+			return "", err
+		}
+		switch respId {
+		case id:
+			response.WriteString(payload)
+		case sentinel:
+			return response.String(), nil
+		}
+	}
+}
+
+func (c *Client) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
@@ -70,11 +70,11 @@ func (rp *REPLPlugin) worker() {
 		line, err := rp.terminal.ReadLine()
 		if err != nil {
 			if err == io.EOF {
-				os.Exit(0)
+				rp.exit()
 			}
 		}
 		if line == "exit" {
-			os.Exit(0)
+			rp.exit()
 		}
 		if len(line) > 0 {
 			rp.RunCommand(line)
@@ -82,6 +82,12 @@ func (rp *REPLPlugin) worker() {
 	}
 }
 
+func (rp *REPLPlugin) exit() {
+	// 退出前关闭插件, 使其保存尚未写入的数据
+	rp.pm.Shutdown()
+	os.Exit(0)
+}
+
 func (rp *REPLPlugin) Pause() {
 
 }
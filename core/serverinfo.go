@@ -0,0 +1,89 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"regexp"
+	"sync"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"github.com/fatih/color"
+)
+
+var ServerVersionMessage = regexp.MustCompile(`Starting minecraft server version (\S+)`)
+var FabricVersionMessage = regexp.MustCompile(`Loading Minecraft (\S+) with Fabric Loader`)
+
+// 按顺序匹配, 先命中者优先
+var ServerFlavorMessage = []struct {
+	flavor string
+	regex  *regexp.Regexp
+}{
+	{pluginabi.ServerFlavorNeoForge, regexp.MustCompile(`--fml\.neoForgeVersion|NeoForge mod loading`)},
+	{pluginabi.ServerFlavorForge, regexp.MustCompile(`--fml\.forgeVersion|Forge mod loading`)},
+	{pluginabi.ServerFlavorFabric, FabricVersionMessage},
+	{pluginabi.ServerFlavorPaper, regexp.MustCompile(`This server is running (?:Paper|Purpur|Pufferfish|Folia) version`)},
+	{pluginabi.ServerFlavorSpigot, regexp.MustCompile(`This server is running CraftBukkit version`)},
+}
+
+type serverInfoDetector struct {
+	info pluginabi.ServerInfo
+	lock sync.RWMutex
+}
+
+func (mpm *MinecraftPluginManager) ServerInfo() pluginabi.ServerInfo {
+	mpm.serverInfo.lock.RLock()
+	defer mpm.serverInfo.lock.RUnlock()
+	return mpm.serverInfo.info
+}
+
+func (mpm *MinecraftPluginManager) resetServerInfo() {
+	mpm.serverInfo.lock.Lock()
+	mpm.serverInfo.info = pluginabi.ServerInfo{}
+	mpm.serverInfo.lock.Unlock()
+}
+
+func (mpm *MinecraftPluginManager) serverInfoProcesser(logText string, _ bool) {
+	version := ServerVersionMessage.FindStringSubmatch(logText)
+	if len(version) != 2 {
+		version = FabricVersionMessage.FindStringSubmatch(logText)
+	}
+	mpm.serverInfo.lock.Lock()
+	defer mpm.serverInfo.lock.Unlock()
+	if len(version) == 2 && mpm.serverInfo.info.Version == "" {
+		mpm.serverInfo.info.Version = version[1]
+	}
+	if mpm.serverInfo.info.Flavor != "" {
+		return
+	}
+	for _, flavor := range ServerFlavorMessage {
+		if flavor.regex.MatchString(logText) {
+			mpm.serverInfo.info.Flavor = flavor.flavor
+			return
+		}
+	}
+}
+
+// 服务器启动完成后仍未识别出加载器则视为原版
+func (mpm *MinecraftPluginManager) finishServerInfo() {
+	mpm.serverInfo.lock.Lock()
+	if mpm.serverInfo.info.Flavor == "" && mpm.serverInfo.info.Version != "" {
+		mpm.serverInfo.info.Flavor = pluginabi.ServerFlavorVanilla
+	}
+	info := mpm.serverInfo.info
+	mpm.serverInfo.lock.Unlock()
+	if info.Flavor != "" {
+		mpm.kPrintln(color.YellowString("服务端: "), color.GreenString(info.Flavor), color.YellowString(" 版本: "), color.GreenString(info.Version))
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"github.com/fatih/color"
+)
+
+// 单个插件 Shutdown 的最长等待时间
+var PluginShutdownTimeout = 10 * time.Second
+
+// 按初始化的逆序暂停并关闭插件, 使依赖核心插件的插件先于核心插件退出
+func (mpm *MinecraftPluginManager) Shutdown() {
+	mpm.shutdownOnce.Do(func() {
+		mpm.kPrintln(color.YellowString("正在关闭插件"))
+		mpm.pluginLock.RLock()
+		plugins := slices.Clone(mpm.initOrder)
+		mpm.pluginLock.RUnlock()
+		slices.Reverse(plugins)
+		for _, pm := range plugins {
+			pm.Pause()
+			shutdowner, ok := pm.plugin.(pluginabi.Shutdowner)
+			if !ok {
+				continue
+			}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				shutdowner.Shutdown()
+			}()
+			select {
+			case <-done:
+			case <-time.After(PluginShutdownTimeout):
+				mpm.kPrintln(color.YellowString("插件 "), color.BlueString(pm.plugin.DisplayName()), color.RedString(" 关闭超时"))
+			}
+		}
+		mpm.kPrintln(color.YellowString("插件已全部关闭"))
+	})
+}
+
+func (mpm *MinecraftPluginManager) handleSignal() {
+	sysSignals := make(chan os.Signal, 1)
+	signal.Notify(sysSignals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sysSignals
+		mpm.Shutdown()
+		os.Exit(0)
+	}()
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/manager"
+	"github.com/fatih/color"
+)
+
+type MinecraftSupervisor struct {
+	AutoRestart bool
+	MaxRestarts int           // Window 内最多重启次数, 默认 3
+	Window      time.Duration // 默认 10 分钟
+	Cooldown    time.Duration // 首次重启前等待时间, 之后每次翻倍, 默认 10 秒
+	Webhook     string        // 可选, 重启时以 Discord 兼容格式推送
+	restarts    []time.Time
+}
+
+func (ms *MinecraftSupervisor) applyDefaults() {
+	if ms.MaxRestarts <= 0 {
+		ms.MaxRestarts = 3
+	}
+	if ms.Window <= 0 {
+		ms.Window = 10 * time.Minute
+	}
+	if ms.Cooldown <= 0 {
+		ms.Cooldown = 10 * time.Second
+	}
+}
+
+// 返回本次重启前的等待时间, 超出次数限制时 ok 为 false
+func (ms *MinecraftSupervisor) nextRestart(now time.Time) (delay time.Duration, ok bool) {
+	ms.applyDefaults()
+	recent := ms.restarts[:0]
+	for _, t := range ms.restarts {
+		if now.Sub(t) < ms.Window {
+			recent = append(recent, t)
+		}
+	}
+	ms.restarts = recent
+	if len(ms.restarts) >= ms.MaxRestarts {
+		return 0, false
+	}
+	delay = ms.Cooldown << len(ms.restarts)
+	ms.restarts = append(ms.restarts, now)
+	return delay, true
+}
+
+func (ms *MinecraftSupervisor) notify(content string) {
+	if ms.Webhook == "" {
+		return
+	}
+	payload, err := json.Marshal(map[string]string{"content": content})
+	if err != nil {
+		return
+	}
+	go func() {
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Post(ms.Webhook, "application/json", bytes.NewReader(payload))
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+}
+
+func (mpm *MinecraftPluginManager) handleCrash() {
+	mpm.minecraftState = manager.MinecraftState_stopped
+	mpm.kPrintln(color.RedString("服务器异常退出，请求停止插件"))
+	mpm.pluginPause()
+	if !mpm.Supervisor.AutoRestart {
+		return
+	}
+	delay, ok := mpm.Supervisor.nextRestart(time.Now())
+	if !ok {
+		msg := fmt.Sprintf("Minecraft 服务器在 %s 内崩溃超过 %d 次, 不再自动重启", mpm.Supervisor.Window, mpm.Supervisor.MaxRestarts)
+		mpm.kPrintln(color.RedString(msg))
+		mpm.Supervisor.notify(msg)
+		return
+	}
+	msg := fmt.Sprintf("Minecraft 服务器崩溃, %s 后自动重启", delay)
+	mpm.kPrintln(color.YellowString(msg))
+	mpm.Supervisor.notify(msg)
+	go func() {
+		time.Sleep(delay)
+		err := mpm.StartMinecraft()
+		if err != nil {
+			mpm.kPrintln(color.RedString("自动重启失败: "), color.MagentaString(err.Error()))
+		}
+	}()
+}
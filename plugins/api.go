@@ -0,0 +1,208 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
+	"github.com/fatih/color"
+)
+
+// 只读 HTTP API
+type APIPlugin struct {
+	plugin.BasePlugin
+	pm     pluginabi.PluginManager
+	Listen string // 默认 127.0.0.1:8080
+	Token  string // 非空时要求 Authorization: Bearer <Token>
+	server *http.Server
+	mux    *http.ServeMux
+
+	eventClients map[*apiPlugin_EventClient]struct{}
+	eventLock    sync.RWMutex
+}
+
+type APIPlugin_Player struct {
+	Online bool
+	Info   *plugin.MinecraftPlayerInfo
+}
+
+type APIPlugin_Status struct {
+	System       *StatusPlugin_SystemStatus
+	Load         map[string]StatusPlugin_LoadSample
+	CommandQueue int
+}
+
+func (ap *APIPlugin) DisplayName() string {
+	return "HTTP API"
+}
+
+func (ap *APIPlugin) Name() string {
+	return "APIPlugin"
+}
+
+func (ap *APIPlugin) Init(pm pluginabi.PluginManager) (err error) {
+	err = ap.BasePlugin.Init(pm, ap)
+	if err != nil {
+		return err
+	}
+	ap.pm = pm
+	if ap.Listen == "" {
+		ap.Listen = "127.0.0.1:8080"
+	}
+	ap.mux = http.NewServeMux()
+	ap.mux.HandleFunc("GET /api/players", ap.players)
+	ap.mux.HandleFunc("GET /api/player/{name}", ap.player)
+	ap.mux.HandleFunc("GET /api/status", ap.status)
+	ap.mux.HandleFunc("GET /api/scoreboard/{objective}", ap.scoreboard)
+	ap.initEvents()
+	return nil
+}
+
+func (ap *APIPlugin) Handle(pattern string, handler http.Handler) {
+	ap.mux.Handle(pattern, handler)
+}
+
+func (ap *APIPlugin) authorize(r *http.Request) bool {
+	if ap.Token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		// 浏览器无法为 WebSocket 设置请求头, 允许通过参数传递
+		token = r.URL.Query().Get("token")
+		ok = token != ""
+	}
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(ap.Token)) == 1
+}
+
+func (ap *APIPlugin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !ap.authorize(r) {
+		ap.writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	ap.mux.ServeHTTP(w, r)
+}
+
+func (ap *APIPlugin) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		ap.Println(color.RedString("API 响应编码失败: "), color.MagentaString(err.Error()))
+	}
+}
+
+func (ap *APIPlugin) writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+func (ap *APIPlugin) players(w http.ResponseWriter, r *http.Request) {
+	players := []APIPlugin_Player{}
+	for _, player := range ap.GetPlayerList() {
+		info, _ := ap.LookupPlayerInfo(player)
+		players = append(players, APIPlugin_Player{Online: true, Info: info})
+	}
+	ap.writeJSON(w, players)
+}
+
+func (ap *APIPlugin) player(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if account, ok := ap.ResolveName(name); ok {
+		name = account
+	}
+	info, ok := ap.LookupPlayerInfo(name)
+	if !ok {
+		ap.writeError(w, http.StatusNotFound, "player not found")
+		return
+	}
+	ap.writeJSON(w, APIPlugin_Player{Online: slices.Contains(ap.GetPlayerList(), info.Player), Info: info})
+}
+
+func (ap *APIPlugin) status(w http.ResponseWriter, r *http.Request) {
+	s, err := plugin.Get[*StatusPlugin](ap.pm)
+	if err != nil {
+		ap.writeError(w, http.StatusServiceUnavailable, "status plugin not loaded")
+		return
+	}
+	ap.writeJSON(w, APIPlugin_Status{System: s.getSystemStatus(), Load: s.latestLoad(), CommandQueue: ap.pm.CommandQueueDepth()})
+}
+
+func (ap *APIPlugin) scoreboard(w http.ResponseWriter, r *http.Request) {
+	sc, err := ap.GetScoreboardCore()
+	if err != nil {
+		ap.writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	objective := r.PathValue("objective")
+	scores := map[string]float64{}
+	found := false
+	for player, playerscope := range sc.GetAllScoresFloat() {
+		if score, ok := playerscope[objective]; ok {
+			scores[player] = score
+			found = true
+		}
+	}
+	if !found {
+		ap.writeError(w, http.StatusNotFound, "objective not found")
+		return
+	}
+	ap.writeJSON(w, scores)
+}
+
+func (ap *APIPlugin) Start() {
+	if ap.server != nil {
+		return
+	}
+	ap.server = &http.Server{Addr: ap.Listen, Handler: ap, ReadHeaderTimeout: 10 * time.Second}
+	go func(server *http.Server) {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ap.Println(color.RedString("HTTP API 启动失败: "), color.MagentaString(err.Error()))
+		}
+	}(ap.server)
+	ap.Println(color.YellowString("HTTP API 已启动: "), color.GreenString("http://%s/api", ap.Listen))
+}
+
+func (ap *APIPlugin) Pause() {
+	ap.Shutdown()
+}
+
+func (ap *APIPlugin) Shutdown() {
+	if ap.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ap.server.Shutdown(ctx)
+	ap.server = nil
+	// WebSocket 连接已被接管, 不受 Shutdown 影响, 需单独关闭
+	ap.eventLock.RLock()
+	for client := range ap.eventClients {
+		client.conn.Close()
+	}
+	ap.eventLock.RUnlock()
+	ap.Println(color.YellowString("HTTP API 已停止"))
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket 可订阅的事件
+var APIPlugin_EventTopics = []string{
+	plugin.EventPlayerChat,
+	plugin.EventPlayerJoin,
+	plugin.EventPlayerLeave,
+	plugin.EventPlayerDeath,
+	plugin.EventLagAlert,
+}
+
+// 每个客户端最多缓存的事件数, 超出后丢弃并在下一条消息前通知丢弃数量
+const APIPlugin_EventBuffer = 64
+
+type APIPlugin_Event struct {
+	Topic string
+	Time  time.Time
+	Data  any
+}
+
+type APIPlugin_EventRequest struct {
+	Subscribe   []string
+	Unsubscribe []string
+}
+
+type apiPlugin_EventClient struct {
+	conn    *websocket.Conn
+	send    chan APIPlugin_Event
+	topics  map[string]bool
+	dropped int
+	lock    sync.Mutex
+}
+
+func (c *apiPlugin_EventClient) setTopics(topics []string, subscribe bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, topic := range topics {
+		c.topics[strings.TrimSpace(topic)] = subscribe
+	}
+}
+
+// 不阻塞事件总线, 缓冲区满时直接丢弃
+func (c *apiPlugin_EventClient) push(event APIPlugin_Event) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.topics[event.Topic] {
+		return
+	}
+	select {
+	case c.send <- event:
+	default:
+		c.dropped++
+	}
+}
+
+func (c *apiPlugin_EventClient) takeDropped() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	dropped := c.dropped
+	c.dropped = 0
+	return dropped
+}
+
+// 设置 Token 时已鉴权, 允许任意来源的面板连接; 未设置时只允许同源连接, 防止任意网页读取事件
+func (ap *APIPlugin) checkOrigin(r *http.Request) bool {
+	if ap.Token != "" {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func (ap *APIPlugin) initEvents() {
+	ap.eventClients = make(map[*apiPlugin_EventClient]struct{})
+	for _, topic := range APIPlugin_EventTopics {
+		ap.Subscribe(topic, func(payload any) {
+			ap.broadcastEvent(APIPlugin_Event{Topic: topic, Time: time.Now(), Data: payload})
+		})
+	}
+	ap.mux.HandleFunc("GET /api/events", ap.events)
+}
+
+func (ap *APIPlugin) broadcastEvent(event APIPlugin_Event) {
+	ap.eventLock.RLock()
+	defer ap.eventLock.RUnlock()
+	for client := range ap.eventClients {
+		client.push(event)
+	}
+}
+
+// 订阅的事件通过 ?topics=a,b 指定, 连接后也可发送 {"Subscribe": [...], "Unsubscribe": [...]} 修改
+func (ap *APIPlugin) events(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: ap.checkOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	client := &apiPlugin_EventClient{conn: conn, send: make(chan APIPlugin_Event, APIPlugin_EventBuffer), topics: make(map[string]bool)}
+	if topics := r.URL.Query().Get("topics"); topics != "" {
+		client.setTopics(strings.Split(topics, ","), true)
+	} else {
+		client.setTopics(APIPlugin_EventTopics, true)
+	}
+	// 连接时先发送当前在线玩家
+	err = conn.WriteJSON(APIPlugin_Event{Topic: "snapshot", Time: time.Now(), Data: ap.GetPlayerList()})
+	if err != nil {
+		conn.Close()
+		return
+	}
+	ap.eventLock.Lock()
+	ap.eventClients[client] = struct{}{}
+	ap.eventLock.Unlock()
+	done := make(chan struct{})
+	go ap.eventReader(client, done)
+	ap.eventWriter(client, done)
+	ap.eventLock.Lock()
+	delete(ap.eventClients, client)
+	ap.eventLock.Unlock()
+	conn.Close()
+}
+
+func (ap *APIPlugin) eventReader(client *apiPlugin_EventClient, done chan struct{}) {
+	defer close(done)
+	for {
+		request := APIPlugin_EventRequest{}
+		err := client.conn.ReadJSON(&request)
+		if err != nil {
+			return
+		}
+		client.setTopics(request.Subscribe, true)
+		client.setTopics(request.Unsubscribe, false)
+	}
+}
+
+func (ap *APIPlugin) eventWriter(client *apiPlugin_EventClient, done chan struct{}) {
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case event := <-client.send:
+			client.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if dropped := client.takeDropped(); dropped > 0 {
+				if client.conn.WriteJSON(APIPlugin_Event{Topic: "dropped", Time: time.Now(), Data: dropped}) != nil {
+					return
+				}
+			}
+			if client.conn.WriteJSON(event) != nil {
+				return
+			}
+		case <-ping.C:
+			err := client.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second))
+			if err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
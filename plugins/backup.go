@@ -41,16 +41,16 @@ type BackupPlugin_RollbackPending interface {
 
 type BackupPlugin struct {
 	plugin.BasePlugin
-	Source                 string // Minecraft world source dir
-	Dest                   string // backup dest
-	backupLock             sync.Mutex
-	rollbackLock           sync.RWMutex
-	cron                   gocron.Scheduler
-	rollbackPending        BackupPlugin_RollbackPending
-	backupPlayerdataTicker *time.Ticker
-	pm                     pluginabi.PluginManager
-	ExtPlayerdataDir       []string
-	ExtPlayerdataExt       []string
+	Source           string // Minecraft world source dir
+	Dest             string // backup dest
+	backupLock       sync.Mutex
+	rollbackLock     sync.RWMutex
+	cron             gocron.Scheduler
+	rollbackPending  BackupPlugin_RollbackPending
+	playerdataStop   chan struct{}
+	pm               pluginabi.PluginManager
+	ExtPlayerdataDir []string
+	ExtPlayerdataExt []string
 }
 
 func (bp *BackupPlugin) DisplayName() string {
@@ -330,12 +330,12 @@ func (bp *BackupPlugin) getBackupList(dirEntry []fs.DirEntry) []string {
 func (bp *BackupPlugin) rollbackPlayerdataList(player string, start string) {
 	pi, err := bp.GetPlayerInfo(player)
 	if err != nil {
-		bp.TellrawError("@a", err)
+		bp.TellrawError(player, err)
 		return
 	}
 	backupFiles, err := os.ReadDir(filepath.Join(bp.Dest, "playerdata", pi.UUID))
 	if err != nil {
-		bp.TellrawError("@a", err)
+		bp.TellrawError(player, err)
 		return
 	}
 	backupList := bp.getBackupList(backupFiles)
@@ -364,10 +364,10 @@ func (bp *BackupPlugin) rollbackPlayerdataList(player string, start string) {
 	}, bp.rollbackPlayerdataList)
 }
 
-func (bp *BackupPlugin) rollbackList(_ string, start string) {
+func (bp *BackupPlugin) rollbackList(player string, start string) {
 	backupFiles, err := os.ReadDir(filepath.Join(bp.Dest, "world"))
 	if err != nil {
-		bp.TellrawError("@a", err)
+		bp.TellrawError(player, err)
 		return
 	}
 	backupList := bp.getBackupList(backupFiles)
@@ -480,24 +480,38 @@ func (bp *BackupPlugin) Init(pm pluginabi.PluginManager) (err error) {
 	return nil
 }
 
-func (bp *BackupPlugin) Start() {
-	bp.cron.Start()
-	if bp.backupPlayerdataTicker == nil {
-		bp.backupPlayerdataTicker = time.NewTicker(60 * time.Second)
-		go func() {
-			for range bp.backupPlayerdataTicker.C {
-				if len(bp.GetPlayerList()) > 0 {
-					bp.MakePlayerDataBackup()
-				}
+func (bp *BackupPlugin) playerdataWorker(stop chan struct{}) {
+	ticker := time.NewTicker(60 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if len(bp.GetPlayerList()) > 0 {
+				bp.MakePlayerDataBackup()
 			}
-		}()
-	} else {
-		bp.backupPlayerdataTicker.Reset(60 * time.Second)
+		case <-stop:
+			return
+		}
 	}
+}
+
+func (bp *BackupPlugin) Start() {
+	bp.cron.Start()
+	bp.stopPlayerdataWorker()
+	bp.playerdataStop = make(chan struct{})
+	go bp.playerdataWorker(bp.playerdataStop)
 	bp.MakePlayerDataBackup()
 }
 
+func (bp *BackupPlugin) stopPlayerdataWorker() {
+	if bp.playerdataStop != nil {
+		close(bp.playerdataStop)
+		bp.playerdataStop = nil
+	}
+}
+
+// 停止 Ticker 不会关闭其 channel, 需通过 playerdataStop 结束 goroutine
 func (bp *BackupPlugin) Pause() {
 	bp.cron.StopJobs()
-	bp.backupPlayerdataTicker.Stop()
+	bp.stopPlayerdataWorker()
 }
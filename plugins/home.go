@@ -66,7 +66,7 @@ func (hp *HomePlugin) home(player string, args ...string) {
 	pi, err := hp.GetPlayerInfo(player)
 	if err != nil {
 		fmt.Println(pi)
-		hp.TellrawError("@a", err)
+		hp.TellrawError(player, err)
 		return
 	}
 	var homeList HomePlugin_HomeList
@@ -125,7 +125,7 @@ func (hp *HomePlugin) sethome(player string, args ...string) {
 	pi, err := hp.GetPlayerInfo_Position(player)
 	if err != nil {
 		fmt.Println(err)
-		hp.TellrawError("@a", err)
+		hp.TellrawError(player, err)
 		return
 	}
 	var homeList HomePlugin_HomeList
@@ -168,7 +168,7 @@ func (hp *HomePlugin) homelist(player string, args ...string) {
 	pi, err := hp.GetPlayerInfo(player)
 	if err != nil {
 		fmt.Println(err)
-		hp.TellrawError("@a", err)
+		hp.TellrawError(player, err)
 		return
 	}
 	var homeList HomePlugin_HomeList
@@ -223,7 +223,7 @@ func (hp *HomePlugin) delhome(player string, args ...string) {
 	pi, err := hp.GetPlayerInfo(player)
 	if err != nil {
 		fmt.Println(err)
-		hp.TellrawError("@a", err)
+		hp.TellrawError(player, err)
 		return
 	}
 	var homeList HomePlugin_HomeList
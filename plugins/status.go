@@ -16,16 +16,18 @@ package plugins
 
 import (
 	"fmt"
-	"log"
 	"math"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core"
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin"
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/command"
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/pluginabi"
 	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
 	"golang.org/x/exp/maps"
@@ -33,6 +35,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/samber/lo"
 	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
 	load "github.com/shirou/gopsutil/v3/load"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/shirou/gopsutil/v3/net"
@@ -42,25 +45,73 @@ type Status_NetStat struct {
 	time         time.Time
 	stat         net.IOCountersStat
 	lastAnnounce time.Time
+	upSpeed      float64 // 最近一次采样的速率 (Mbps), rateOk 为 false 时尚未计算
+	downSpeed    float64
+	rateOk       bool
 }
 
 type StatusPlugin struct {
 	plugin.BasePlugin
-	pm                pluginabi.PluginManager
-	LastBroadcastMspt float64
-	LastMspt          []float64
-	ForgeTpsCommand   string
-	monitorStop       chan struct{}
-	MaxSentBandwidth  float64 // Mbps
-	MaxRecvBandwidth  float64 // Mbps
-	lastnetStat       *Status_NetStat
+	pm                   pluginabi.PluginManager
+	LastBroadcastMspt    float64
+	LastMspt             []float64
+	ForgeTpsCommand      string
+	ServerFlavor         string        // forge, paper, spigot, vanilla, carpet; 为空时在 Start 时检测
+	TpsRetryInterval     time.Duration // 未找到 TPS 命令时重新检测的间隔, 默认 5 分钟
+	tpsLock              sync.RWMutex
+	MsptThreshold        StatusPlugin_Threshold // 默认 55ms/65ms
+	LoadThreshold        StatusPlugin_Threshold // 默认 0.4/0.7
+	AlertSlope           float64                // MSPT 趋势斜率超过该值时告警, 默认 2.0
+	AlertDelta           float64                // 与上次告警的 MSPT 差值超过该值时告警, 默认 8ms
+	AlertInterval        time.Duration          // 两次告警的最小间隔, 默认不限制
+	lastAlert            time.Time
+	MonitorMinInterval   time.Duration // MSPT 上升时采样间隔逐步缩短到该值, 默认 2s
+	MonitorMaxInterval   time.Duration // 负载平稳时采样间隔逐步延长到该值, 默认 10s
+	monitorStop          chan struct{}
+	MaxSentBandwidth     float64 // Mbps
+	MaxRecvBandwidth     float64 // Mbps
+	DiskPath             string  // 监控磁盘占用的路径, 默认为工作目录
+	BarWidth             int     // 占用条宽度 (字符数), 默认 32
+	UnicodeBar           bool    // 使用 ▏▎▍▌▋▊▉█ 绘制占用条, 精度为 1/8 字符
+	lastnetStat          *Status_NetStat
+	netLock              sync.Mutex
+	MetricsListen        string        // Prometheus 监听地址, 为空时不启用
+	AlertWebhook         string        // Discord 兼容的 Webhook 地址, 为空时不启用
+	AlertWebhookCooldown time.Duration // 默认 5 分钟
+	lastWebhook          time.Time
+	webhookLock          sync.Mutex
+	exporter             atomic.Pointer[StatusPlugin_Exporter] // Start/Pause 与监控协程并发访问
+	history              map[string][]StatusPlugin_LoadSample
+	historyLock          sync.RWMutex
+	heap                 StatusPlugin_JvmHeap
+	heapLock             sync.RWMutex
 }
 
+// 小于 Yellow 为绿色, 小于 Red 为黄色, 其余为红色
+type StatusPlugin_Threshold struct {
+	Yellow float64
+	Red    float64
+}
+
+type StatusPlugin_LoadSample struct {
+	Time time.Time
+	MSPT float64
+	TPS  float64
+}
+
+// 网络速率超过该时间未更新时视为不可用
+const StatusPlugin_NetRateMaxAge = 5 * time.Second
+
+// 按时间保留负载历史, 采样间隔随负载变化, 不按样本数限制
+const StatusPlugin_HistoryRetention = time.Hour
+
 type StatusPlugin_MinecraftLoad struct {
-	World string
-	MSPT  float64
-	TPS   float64
-	index int
+	World       string
+	MSPT        float64
+	TPS         float64
+	EntityCount int // -1 表示不支持
+	ChunkCount  int // -1 表示不支持
+	index       int
 }
 
 func (s *StatusPlugin) DisplayName() string {
@@ -77,28 +128,196 @@ func (s *StatusPlugin) Init(pm pluginabi.PluginManager) (err error) {
 	if err != nil {
 		return err
 	}
+	s.history = make(map[string][]StatusPlugin_LoadSample)
+	s.MsptThreshold = s.validateThreshold("MSPT", s.MsptThreshold, StatusPlugin_Threshold{Yellow: 55, Red: 65})
+	s.LoadThreshold = s.validateThreshold("负载", s.LoadThreshold, StatusPlugin_Threshold{Yellow: 0.4, Red: 0.7})
+	if s.AlertSlope <= 0 {
+		if s.AlertSlope < 0 {
+			s.Println(color.RedString("无效的告警斜率 "), color.MagentaString("%v", s.AlertSlope), color.RedString(", 使用默认值"))
+		}
+		s.AlertSlope = 2.0
+	}
+	if s.AlertDelta <= 0 {
+		if s.AlertDelta < 0 {
+			s.Println(color.RedString("无效的告警 MSPT 差值 "), color.MagentaString("%v", s.AlertDelta), color.RedString(", 使用默认值"))
+		}
+		s.AlertDelta = 8
+	}
+	if s.AlertInterval < 0 {
+		s.Println(color.RedString("无效的告警间隔 "), color.MagentaString("%v", s.AlertInterval), color.RedString(", 不限制告警间隔"))
+		s.AlertInterval = 0
+	}
+	if s.DiskPath == "" {
+		s.DiskPath = "."
+	}
+	if s.TpsRetryInterval <= 0 {
+		s.TpsRetryInterval = 5 * time.Minute
+	}
+	if s.BarWidth <= 0 {
+		s.BarWidth = 32
+	}
+	if s.AlertWebhookCooldown <= 0 {
+		s.AlertWebhookCooldown = 5 * time.Minute
+	}
+	if s.MonitorMinInterval <= 0 {
+		s.MonitorMinInterval = 2 * time.Second
+	}
+	if s.MonitorMaxInterval <= 0 {
+		s.MonitorMaxInterval = 10 * time.Second
+	}
+	if s.MonitorMinInterval > s.MonitorMaxInterval {
+		s.Println(color.RedString("最小采样间隔 "), color.MagentaString("%s", s.MonitorMinInterval), color.RedString(" 大于最大采样间隔, 使用固定间隔"))
+		s.MonitorMinInterval = s.MonitorMaxInterval
+	}
+	pm.RegisterLogProcesser(s, s.gcLogProcesser)
 	s.RegisterCommand("status", s.status)
+	s.RegisterCommandCompleter("status", s.statusCompleter)
 	s.monitorSystem()
 	return nil
 }
 
 var StatusPlugin_ParseLoad = regexp.MustCompile(`(?:Dim )?(.*?)[ ]?(?:\(.*?\))?: Mean tick time:.(.*?).ms.*?TPS:.(.{6})`)
+var StatusPlugin_ParsePaperMspt = regexp.MustCompile(`(\d+(?:\.\d+)?)/\d+(?:\.\d+)?/\d+(?:\.\d+)?`)
+var StatusPlugin_ParseSpigotTps = regexp.MustCompile(`TPS from last 1m, 5m, 15m: \*?(\d+(?:\.\d+)?)`)
+var StatusPlugin_ParseVanillaMspt = regexp.MustCompile(`Average time per tick: (\d+(?:\.\d+)?)ms`)
+var StatusPlugin_ParseCarpetMspt = regexp.MustCompile(` = (\d+(?:\.\d+)?)`)
+var StatusPlugin_ParseEntityCount = regexp.MustCompile(`Test passed, count: (\d+)`)
+var StatusPlugin_ParsePaperChunks = regexp.MustCompile(`Total: (\d+)`)
+var StatusPlugin_ColorCode = regexp.MustCompile(`§.`)
+
+const (
+	StatusPlugin_FlavorForge   = "forge"
+	StatusPlugin_FlavorPaper   = "paper"
+	StatusPlugin_FlavorSpigot  = "spigot"
+	StatusPlugin_FlavorVanilla = "vanilla"
+	StatusPlugin_FlavorCarpet  = "carpet"
+)
+
+// Carpet 的 tick health 为异步输出, 改用 scarpet 计算最近 100 tick 的平均耗时
+const StatusPlugin_CarpetTpsCommand = "script run t = system_info('server_last_tick_times'); reduce(t, _a + _, 0) / length(t)"
+
+// 检测期间会被 monitorWorker 修改, 需通过此方法读取
+func (s *StatusPlugin) tpsCommand() (command string, flavor string) {
+	s.tpsLock.RLock()
+	defer s.tpsLock.RUnlock()
+	return s.ForgeTpsCommand, s.ServerFlavor
+}
+
+func (s *StatusPlugin) tpsAvailable() bool {
+	command, _ := s.tpsCommand()
+	return command != ""
+}
 
 func (s *StatusPlugin) getMinecraftLoad() map[string]StatusPlugin_MinecraftLoad {
+	command, flavor := s.tpsCommand()
+	if command == "" {
+		return make(map[string]StatusPlugin_MinecraftLoad)
+	}
+	switch flavor {
+	case StatusPlugin_FlavorPaper, StatusPlugin_FlavorSpigot, StatusPlugin_FlavorVanilla, StatusPlugin_FlavorCarpet:
+		return s.getOverallLoad(command, flavor)
+	}
+	return s.getForgeLoad(command)
+}
+
+// Paper/Spigot/Carpet/原版只提供整个服务器的负载
+func (s *StatusPlugin) getOverallLoad(command string, flavor string) map[string]StatusPlugin_MinecraftLoad {
 	loadList := make(map[string]StatusPlugin_MinecraftLoad)
-	worldStatusPlugin := StatusPlugin_ParseLoad.FindAllStringSubmatch(s.RunCommand(s.ForgeTpsCommand), -1)
+	res := StatusPlugin_ColorCode.ReplaceAllString(s.RunCommand(command), "")
+	var MSPT, TPS float64
+	switch flavor {
+	case StatusPlugin_FlavorPaper:
+		match := StatusPlugin_ParsePaperMspt.FindStringSubmatch(res)
+		if match == nil {
+			return loadList
+		}
+		MSPT, _ = strconv.ParseFloat(match[1], 64)
+		TPS = math.Min(20, 1000/MSPT)
+	case StatusPlugin_FlavorSpigot:
+		match := StatusPlugin_ParseSpigotTps.FindStringSubmatch(res)
+		if match == nil {
+			return loadList
+		}
+		TPS, _ = strconv.ParseFloat(match[1], 64)
+		if TPS <= 0 {
+			return loadList
+		}
+		TPS = math.Min(20, TPS)
+		// Spigot 不提供 MSPT, 由 TPS 估算
+		MSPT = 1000 / TPS
+	case StatusPlugin_FlavorVanilla:
+		match := StatusPlugin_ParseVanillaMspt.FindStringSubmatch(res)
+		if match == nil {
+			return loadList
+		}
+		MSPT, _ = strconv.ParseFloat(match[1], 64)
+		TPS = math.Min(20, 1000/MSPT)
+	case StatusPlugin_FlavorCarpet:
+		match := StatusPlugin_ParseCarpetMspt.FindStringSubmatch(res)
+		if match == nil {
+			return loadList
+		}
+		MSPT, _ = strconv.ParseFloat(match[1], 64)
+		TPS = math.Min(20, 1000/MSPT)
+	}
+	loadList["Overall"] = StatusPlugin_MinecraftLoad{World: "Overall", MSPT: MSPT, TPS: TPS, EntityCount: -1, ChunkCount: -1}
+	return loadList
+}
+
+func (s *StatusPlugin) getEntityCount(world string) int {
+	command := "execute if entity @e"
+	if world != "Overall" {
+		// 指定坐标与距离, 使选择器只匹配该维度内的实体
+		command = fmt.Sprintf("execute in %s if entity @e[x=0,y=0,z=0,distance=0..]", world)
+	}
+	res := s.RunCommand(command)
+	if core.UnknownCommand.MatchString(res) {
+		return -1
+	}
+	match := StatusPlugin_ParseEntityCount.FindStringSubmatch(res)
+	if match == nil {
+		if strings.Contains(res, "Test failed") {
+			return 0
+		}
+		return -1
+	}
+	count, _ := strconv.Atoi(match[1])
+	return count
+}
+
+func (s *StatusPlugin) getChunkCount(world string) int {
+	// 仅 Paper 提供区块统计命令
+	if _, flavor := s.tpsCommand(); flavor != StatusPlugin_FlavorPaper || world != "Overall" {
+		return -1
+	}
+	res := StatusPlugin_ColorCode.ReplaceAllString(s.RunCommand("paper chunkinfo *"), "")
+	match := StatusPlugin_ParsePaperChunks.FindAllStringSubmatch(res, -1)
+	if len(match) == 0 {
+		return -1
+	}
+	count, _ := strconv.Atoi(match[len(match)-1][1])
+	return count
+}
+
+func (s *StatusPlugin) getForgeLoad(command string) map[string]StatusPlugin_MinecraftLoad {
+	loadList := make(map[string]StatusPlugin_MinecraftLoad)
+	worldStatusPlugin := StatusPlugin_ParseLoad.FindAllStringSubmatch(s.RunCommand(command), -1)
 	for idx, match := range worldStatusPlugin {
 		World, MSPTStr := match[1], match[2]
 		World = strings.ReplaceAll(World, "(", "")
 		World = strings.ReplaceAll(World, ")", "")
 		MSPT, _ := strconv.ParseFloat(MSPTStr, 64)
 		TPS := math.Min(20, 1000/MSPT)
-		loadList[World] = StatusPlugin_MinecraftLoad{World, MSPT, TPS, idx}
+		loadList[World] = StatusPlugin_MinecraftLoad{World: World, MSPT: MSPT, TPS: TPS, EntityCount: -1, ChunkCount: -1, index: idx}
 	}
 	return loadList
 }
 
 func (s *StatusPlugin) leastsquares(series []float64) float64 {
+	// 少于两个样本时无法计算趋势
+	if len(series) < 2 {
+		return 0
+	}
 	xAvg := (1 + float64(len(series))) / 2
 	yAvg := 0.0
 	for _, val := range series {
@@ -113,76 +332,134 @@ func (s *StatusPlugin) leastsquares(series []float64) float64 {
 		xSquareSum += math.Pow(float64(i+1), 2)
 	}
 
-	return (xySum - float64(len(series))*xAvg*yAvg) / (xSquareSum - float64(len(series))*math.Pow(xAvg, 2))
+	denominator := xSquareSum - float64(len(series))*math.Pow(xAvg, 2)
+	if denominator == 0 {
+		return 0
+	}
+	K := (xySum - float64(len(series))*xAvg*yAvg) / denominator
+	if math.IsNaN(K) || math.IsInf(K, 0) {
+		return 0
+	}
+	return K
 }
 
-func (s *StatusPlugin) floatLevel(f float64) tellraw.Color {
-	if f < 0.4 {
+func (s *StatusPlugin) validateThreshold(name string, threshold StatusPlugin_Threshold, defaultThreshold StatusPlugin_Threshold) StatusPlugin_Threshold {
+	if threshold == (StatusPlugin_Threshold{}) {
+		return defaultThreshold
+	}
+	if threshold.Yellow <= 0 || threshold.Yellow >= threshold.Red {
+		s.Println(color.RedString("无效的 "), color.BlueString(name), color.RedString(" 阈值 "), color.MagentaString("%v/%v", threshold.Yellow, threshold.Red), color.RedString(", 使用默认值 "), color.GreenString("%v/%v", defaultThreshold.Yellow, defaultThreshold.Red))
+		return defaultThreshold
+	}
+	return threshold
+}
+
+func (t StatusPlugin_Threshold) level(v float64) tellraw.Color {
+	if v < t.Yellow {
 		return tellraw.Green
 	}
-	if f < 0.7 {
+	if v < t.Red {
 		return tellraw.Yellow
 	}
 	return tellraw.Red
 }
 
+func (s *StatusPlugin) floatLevel(f float64) tellraw.Color {
+	return s.LoadThreshold.level(f)
+}
+
 func (s *StatusPlugin) msptLevel(mspt float64) tellraw.Color {
-	if mspt < 55 {
-		return tellraw.Green
+	return s.MsptThreshold.level(mspt)
+}
+
+var StatusPlugin_BarBlocks = []rune("▏▎▍▌▋▊▉█")
+
+// 已用部分为红色, 剩余部分为绿色, usage 范围 0~1
+func (s *StatusPlugin) usageBar(usage float64, hover *tellraw.HoverEvent) []tellraw.Message {
+	usage = min(max(usage, 0), 1)
+	var used, free string
+	if s.UnicodeBar {
+		full := int(usage * float64(s.BarWidth))
+		eighths := int(math.RoundToEven((usage*float64(s.BarWidth) - float64(full)) * 8))
+		if eighths == 8 {
+			full, eighths = full+1, 0
+		}
+		used = strings.Repeat("█", full)
+		if eighths > 0 {
+			used += string(StatusPlugin_BarBlocks[eighths-1])
+			full++
+		}
+		free = strings.Repeat("█", max(s.BarWidth-full, 0))
+	} else {
+		bar := int(math.RoundToEven(usage * float64(s.BarWidth)))
+		used = strings.Repeat("|", bar)
+		free = strings.Repeat("|", max(s.BarWidth-bar, 0))
 	}
-	if mspt < 65 {
-		return tellraw.Yellow
+	return []tellraw.Message{
+		{Text: "[", Color: tellraw.Yellow},
+		{Text: used, Color: tellraw.Red, HoverEvent: hover},
+		{Text: free, Color: tellraw.Green, HoverEvent: hover},
+		{Text: "]", Color: tellraw.Yellow},
 	}
-	return tellraw.Red
 }
 
 func (s *StatusPlugin) monitorSystem() {
-	cpu.Percent(0, true)
+	if exporter := s.exporter.Load(); exporter != nil {
+		exporter.updateSystem(s.getSystemStatus())
+	} else {
+		cpu.Percent(0, true)
+	}
 	now := time.Now()
 	netio, err := s.getNetio()
 	if err != nil {
 		return
 	}
-	if s.lastnetStat != nil {
-		upSpeed := float64(netio.BytesSent-s.lastnetStat.stat.BytesSent) * 8.0 / float64(now.Sub(s.lastnetStat.time).Seconds()) / 1024.0 / 1024.0
-		downSpeed := float64(netio.BytesRecv-s.lastnetStat.stat.BytesRecv) * 8.0 / float64(now.Sub(s.lastnetStat.time).Seconds()) / 1024.0 / 1024.0
+	announce := false
+	var upSpeed, downSpeed float64
+	s.netLock.Lock()
+	if last := s.lastnetStat; last != nil {
+		upSpeed = float64(netio.BytesSent-last.stat.BytesSent) * 8.0 / float64(now.Sub(last.time).Seconds()) / 1024.0 / 1024.0
+		downSpeed = float64(netio.BytesRecv-last.stat.BytesRecv) * 8.0 / float64(now.Sub(last.time).Seconds()) / 1024.0 / 1024.0
+		last.upSpeed, last.downSpeed, last.rateOk = upSpeed, downSpeed, true
 		if (s.MaxSentBandwidth-upSpeed) < s.MaxSentBandwidth*0.2 || (s.MaxRecvBandwidth-downSpeed) < s.MaxRecvBandwidth*0.2 {
-			if now.Sub(s.lastnetStat.lastAnnounce).Seconds() > 30 && now.Sub(s.lastnetStat.time).Milliseconds() > 500 {
-				s.Println(color.RedString("网络过载："), color.MagentaString("%.2f", upSpeed), color.YellowString(" Mbps↑ "), color.MagentaString("%.2f", downSpeed), color.YellowString(" Mbps↓"))
-				s.lastnetStat.lastAnnounce = now
-				s.Tellraw(`@a`, []tellraw.Message{
-					{Text: "检测到网络带宽到达上限", Color: tellraw.Red},
-				})
-				s.Tellraw(`@a`, []tellraw.Message{
-					{Text: "地图加载可能出现延迟", Color: tellraw.Aqua},
-				})
-				s.Tellraw(`@a`, []tellraw.Message{
-					{Text: "网络负载: ", Color: tellraw.Aqua},
-				})
-				s.Tellraw(`@a`, []tellraw.Message{
-					{Text: "上传: ", Color: tellraw.Yellow},
-					{Text: fmt.Sprintf("%.2f", upSpeed), Color: s.floatLevel(upSpeed / s.MaxSentBandwidth)},
-					{Text: " Mbps", Color: tellraw.Yellow},
-					{Text: "↑", Color: tellraw.Aqua},
-					{Text: fmt.Sprintf("(%.2f%%)", upSpeed/s.MaxSentBandwidth*100), Color: s.floatLevel(upSpeed / s.MaxSentBandwidth)},
-				})
-				s.Tellraw(`@a`, []tellraw.Message{
-					{Text: "下载: ", Color: tellraw.Yellow},
-					{Text: fmt.Sprintf("%.2f", downSpeed), Color: s.floatLevel(downSpeed / s.MaxRecvBandwidth)},
-					{Text: " Mbps", Color: tellraw.Yellow},
-					{Text: "↓", Color: tellraw.Aqua},
-					{Text: fmt.Sprintf("(%.2f%%)", downSpeed/s.MaxRecvBandwidth*100), Color: s.floatLevel(downSpeed / s.MaxRecvBandwidth)},
-				})
+			if now.Sub(last.lastAnnounce).Seconds() > 30 && now.Sub(last.time).Milliseconds() > 500 {
+				announce = true
+				last.lastAnnounce = now
 			}
 		}
 	} else {
-		s.lastnetStat = &Status_NetStat{
-			time: now,
-			stat: netio,
-		}
+		s.lastnetStat = &Status_NetStat{}
 	}
 	s.lastnetStat.time = now
 	s.lastnetStat.stat = netio
+	s.netLock.Unlock()
+	if !announce {
+		return
+	}
+	s.Println(color.RedString("网络过载："), color.MagentaString("%.2f", upSpeed), color.YellowString(" Mbps↑ "), color.MagentaString("%.2f", downSpeed), color.YellowString(" Mbps↓"))
+	s.Tellraw(`@a`, []tellraw.Message{
+		{Text: "检测到网络带宽到达上限", Color: tellraw.Red},
+	})
+	s.Tellraw(`@a`, []tellraw.Message{
+		{Text: "地图加载可能出现延迟", Color: tellraw.Aqua},
+	})
+	s.Tellraw(`@a`, []tellraw.Message{
+		{Text: "网络负载: ", Color: tellraw.Aqua},
+	})
+	s.Tellraw(`@a`, []tellraw.Message{
+		{Text: "上传: ", Color: tellraw.Yellow},
+		{Text: fmt.Sprintf("%.2f", upSpeed), Color: s.floatLevel(upSpeed / s.MaxSentBandwidth)},
+		{Text: " Mbps", Color: tellraw.Yellow},
+		{Text: "↑", Color: tellraw.Aqua},
+		{Text: fmt.Sprintf("(%.2f%%)", upSpeed/s.MaxSentBandwidth*100), Color: s.floatLevel(upSpeed / s.MaxSentBandwidth)},
+	})
+	s.Tellraw(`@a`, []tellraw.Message{
+		{Text: "下载: ", Color: tellraw.Yellow},
+		{Text: fmt.Sprintf("%.2f", downSpeed), Color: s.floatLevel(downSpeed / s.MaxRecvBandwidth)},
+		{Text: " Mbps", Color: tellraw.Yellow},
+		{Text: "↓", Color: tellraw.Aqua},
+		{Text: fmt.Sprintf("(%.2f%%)", downSpeed/s.MaxRecvBandwidth*100), Color: s.floatLevel(downSpeed / s.MaxRecvBandwidth)},
+	})
 }
 
 func (s *StatusPlugin) getNetio() (o net.IOCountersStat, err error) {
@@ -206,8 +483,148 @@ func (s *StatusPlugin) getNetio() (o net.IOCountersStat, err error) {
 	return
 }
 
+func (s *StatusPlugin) recordHistory(load map[string]StatusPlugin_MinecraftLoad) {
+	now := time.Now()
+	s.historyLock.Lock()
+	defer s.historyLock.Unlock()
+	for world, worldLoad := range load {
+		samples := s.history[world]
+		expired, _ := slices.BinarySearchFunc(samples, now.Add(-StatusPlugin_HistoryRetention), func(sample StatusPlugin_LoadSample, t time.Time) int {
+			return sample.Time.Compare(t)
+		})
+		samples = slices.Delete(samples, 0, expired)
+		s.history[world] = append(samples, StatusPlugin_LoadSample{Time: now, MSPT: worldLoad.MSPT, TPS: worldLoad.TPS})
+	}
+}
+
+func (s *StatusPlugin) latestLoad() map[string]StatusPlugin_LoadSample {
+	s.historyLock.RLock()
+	defer s.historyLock.RUnlock()
+	latest := make(map[string]StatusPlugin_LoadSample)
+	for world, samples := range s.history {
+		if len(samples) > 0 {
+			latest[world] = samples[len(samples)-1]
+		}
+	}
+	return latest
+}
+
+func (s *StatusPlugin) getHistory(world string, since time.Time) []StatusPlugin_LoadSample {
+	s.historyLock.RLock()
+	defer s.historyLock.RUnlock()
+	samples, ok := s.history[world]
+	if !ok {
+		samples = s.history["minecraft:"+world]
+	}
+	return lo.Filter(samples, func(sample StatusPlugin_LoadSample, _ int) bool {
+		return !sample.Time.Before(since)
+	})
+}
+
+var StatusPlugin_SparklineChar = []rune("▁▂▃▄▅▆▇█")
+
+const StatusPlugin_SparklineSamples = 30
+
+func (s *StatusPlugin) msptSparkline(world string) *tellraw.HoverEvent {
+	samples := s.getHistory(world, time.Time{})
+	if len(samples) < 2 {
+		return nil
+	}
+	samples = samples[max(len(samples)-StatusPlugin_SparklineSamples, 0):]
+	mspt := lo.Map(samples, func(sample StatusPlugin_LoadSample, _ int) float64 { return sample.MSPT })
+	msptMin, msptMax := slices.Min(mspt), slices.Max(mspt)
+	sparkline := lo.Map(mspt, func(v float64, _ int) tellraw.Message {
+		level := 0
+		if msptMax > msptMin {
+			level = int(math.Round((v - msptMin) / (msptMax - msptMin) * float64(len(StatusPlugin_SparklineChar)-1)))
+		}
+		return tellraw.Message{Text: string(StatusPlugin_SparklineChar[level]), Color: s.msptLevel(v)}
+	})
+	return &tellraw.HoverEvent{
+		Action: tellraw.Show_Text,
+		Contents: append([]tellraw.Message{
+			{Text: fmt.Sprintf("最近 %d 个 MSPT 样本 ", len(mspt)), Color: tellraw.Aqua},
+			{Text: fmt.Sprintf("%.2f~%.2fms\n", msptMin, msptMax), Color: tellraw.Yellow},
+		}, sparkline...),
+	}
+}
+
+func (s *StatusPlugin) statusCompleter(args []string) []string {
+	switch len(args) {
+	case 1:
+		return []string{"history"}
+	case 2:
+		if args[0] == "history" {
+			s.historyLock.RLock()
+			defer s.historyLock.RUnlock()
+			return maps.Keys(s.history)
+		}
+	}
+	return nil
+}
+
+var statusHistoryCommand = command.New("status history",
+	command.Arg("world", command.String, command.Default("Overall")),
+	command.Arg("minutes", command.Int, command.Default(60)),
+)
+
+func (s *StatusPlugin) statusHistory(player string, args ...string) {
+	values, err := statusHistoryCommand.Parse(args)
+	if err != nil {
+		if usage, ok := err.(*command.UsageError); ok {
+			s.Tellraw(player, usage.Tellraw())
+		}
+		return
+	}
+	world := values.String("world")
+	minutes := values.Int("minutes")
+	if minutes <= 0 {
+		s.Tellraw(player, []tellraw.Message{{Text: "无效的时间: ", Color: tellraw.Red}, {Text: strconv.Itoa(minutes), Color: tellraw.Yellow}})
+		return
+	}
+	samples := s.getHistory(world, time.Now().Add(-time.Duration(minutes)*time.Minute))
+	if len(samples) == 0 {
+		s.Tellraw(player, []tellraw.Message{{Text: "没有 ", Color: tellraw.Red}, {Text: s.GetWorldName(world), Color: tellraw.Yellow}, {Text: " 的历史数据", Color: tellraw.Red}})
+		return
+	}
+	mspt := lo.Map(samples, func(sample StatusPlugin_LoadSample, _ int) float64 { return sample.MSPT })
+	tps := lo.Map(samples, func(sample StatusPlugin_LoadSample, _ int) float64 { return sample.TPS })
+	msptAvg := lo.Sum(mspt) / float64(len(mspt))
+	tpsAvg := lo.Sum(tps) / float64(len(tps))
+	s.Tellraw(player, []tellraw.Message{
+		{Text: "============ 负载历史 ============", Color: tellraw.Green},
+	})
+	s.Tellraw(player, []tellraw.Message{
+		{Text: `世界: `, Color: tellraw.Aqua},
+		{Text: s.GetWorldName(world), Color: tellraw.Green, Bold: true},
+		{Text: fmt.Sprintf(" 最近 %d 分钟 (%d 个样本)", minutes, len(samples)), Color: tellraw.Yellow},
+	})
+	s.Tellraw(player, []tellraw.Message{
+		{Text: `MSPT: `, Color: tellraw.Aqua},
+		{Text: "最小 ", Color: tellraw.Yellow},
+		{Text: fmt.Sprintf("%.2fms", slices.Min(mspt)), Color: s.msptLevel(slices.Min(mspt))},
+		{Text: " 平均 ", Color: tellraw.Yellow},
+		{Text: fmt.Sprintf("%.2fms", msptAvg), Color: s.msptLevel(msptAvg)},
+		{Text: " 最大 ", Color: tellraw.Yellow},
+		{Text: fmt.Sprintf("%.2fms", slices.Max(mspt)), Color: s.msptLevel(slices.Max(mspt))},
+	})
+	s.Tellraw(player, []tellraw.Message{
+		{Text: `TPS: `, Color: tellraw.Aqua},
+		{Text: "最小 ", Color: tellraw.Yellow},
+		{Text: fmt.Sprintf("%.2f", slices.Min(tps)), Color: s.msptLevel(slices.Max(mspt))},
+		{Text: " 平均 ", Color: tellraw.Yellow},
+		{Text: fmt.Sprintf("%.2f", tpsAvg), Color: s.msptLevel(msptAvg)},
+		{Text: " 最大 ", Color: tellraw.Yellow},
+		{Text: fmt.Sprintf("%.2f", slices.Max(tps)), Color: s.msptLevel(slices.Min(mspt))},
+	})
+}
+
 func (s *StatusPlugin) monitorGame() {
 	load := s.getMinecraftLoad()
+	s.recordHistory(load)
+	if exporter := s.exporter.Load(); exporter != nil {
+		exporter.updateLoad(load)
+	}
 	overall, ok := load["Overall"]
 	if !ok {
 		return
@@ -217,8 +634,8 @@ func (s *StatusPlugin) monitorGame() {
 	}
 	s.LastMspt = append(s.LastMspt, overall.MSPT)
 	K := s.leastsquares(s.LastMspt)
-	if math.Abs(K) > 2.0 {
-		if K > 0 && math.Abs(slices.Max(s.LastMspt)-s.LastBroadcastMspt) > 8 {
+	if math.Abs(K) > s.AlertSlope && time.Since(s.lastAlert) >= s.AlertInterval {
+		if K > 0 && math.Abs(slices.Max(s.LastMspt)-s.LastBroadcastMspt) > s.AlertDelta {
 			s.LastBroadcastMspt = slices.Max(s.LastMspt)
 			s.Tellraw(`@a`, []tellraw.Message{
 				{
@@ -227,75 +644,161 @@ func (s *StatusPlugin) monitorGame() {
 					Bold:  true,
 				},
 			})
-		} else if K < 0 && math.Abs(slices.Min(s.LastMspt)-s.LastBroadcastMspt) > 8 {
+			s.sendWebhookAlert("检测到服务器负载增加", true, "Overall", overall, K)
+		} else if K < 0 && math.Abs(slices.Min(s.LastMspt)-s.LastBroadcastMspt) > s.AlertDelta {
 			s.LastBroadcastMspt = slices.Min(s.LastMspt)
 			s.Tellraw(`@a`, []tellraw.Message{
 				{
 					Text:  `检测到服务器负载减少`,
-					Color: "green",
+					Color: tellraw.Green,
 					Bold:  true,
 				},
 			})
+			s.sendWebhookAlert("检测到服务器负载减少", false, "Overall", overall, K)
 		} else {
 			return
 		}
+		s.lastAlert = time.Now()
+		s.Publish(plugin.EventLagAlert, plugin.LagAlertEvent{Increase: K > 0, World: "Overall", MSPT: overall.MSPT, TPS: overall.TPS, Slope: K})
 		s.Tellraw(`@a`, []tellraw.Message{
 			{Text: `世界: `, Color: tellraw.Aqua},
 			{Text: "服务器", Color: tellraw.Green, Bold: true},
-			{Text: ` TPS: `, Color: "aqua"},
+			{Text: ` TPS: `, Color: tellraw.Aqua},
 			{Text: fmt.Sprintf("%.2f", overall.TPS), Color: s.msptLevel(overall.MSPT)},
-			{Text: ` MSPT: `, Color: "aqua"},
+			{Text: ` MSPT: `, Color: tellraw.Aqua},
 			{Text: fmt.Sprintf("%.2fms", overall.MSPT), Color: s.msptLevel(overall.MSPT)},
-			{Text: ` 负载: `, Color: "aqua"},
+			{Text: ` 负载: `, Color: tellraw.Aqua},
 			{Text: fmt.Sprintf(`%.2f%%`, overall.MSPT/50*100), Color: s.msptLevel(overall.MSPT)},
 		})
 	}
 }
 
-func (s *StatusPlugin) status(player string, args ...string) {
+type StatusPlugin_SystemStatus struct {
+	CPUCount     int
+	CPUUsage     []float64
+	CPUUsageAvg  float64
+	Load         *load.AvgStat
+	Memory       *mem.VirtualMemoryStat
+	Disk         *disk.UsageStat
+	GameMemory   uint64
+	GameMemoryOk bool
+}
+
+func (s *StatusPlugin) getSystemStatus() *StatusPlugin_SystemStatus {
+	system := &StatusPlugin_SystemStatus{}
+	system.CPUCount, _ = cpu.Counts(true)
+	cpuUsage, err := cpu.Percent(0, true)
+	if err == nil && len(cpuUsage) > 0 {
+		system.CPUUsage = cpuUsage
+		system.CPUUsageAvg = lo.Sum(cpuUsage) / float64(len(cpuUsage)) / 100.0
+	}
+	if systemLoad, err := load.Avg(); err == nil {
+		system.Load = systemLoad
+	}
+	if sysMem, err := mem.VirtualMemory(); err == nil {
+		system.Memory = sysMem
+	}
+	if diskUsage, err := disk.Usage(s.DiskPath); err == nil && diskUsage.Total > 0 {
+		system.Disk = diskUsage
+	}
+	if minecraftStatus, err := s.pm.Status(); err == nil {
+		system.GameMemory = minecraftStatus.Usedmemory
+		system.GameMemoryOk = true
+	}
+	return system
+}
+
+// status 命令展示的数据, 不支持的项为零值或 nil
+type StatusPlugin_Snapshot struct {
+	Time         time.Time
+	CPUCount     int
+	CPU          []float64     // 各核心使用率 (%)
+	CPUAvg       float64       // 平均使用率 (0~1)
+	LoadAvg      *load.AvgStat // 系统负载
+	MemUsed      uint64        // 系统内存 (字节), MemTotal 为 0 表示不可用
+	MemTotal     uint64
+	GameMemory   uint64 // 游戏进程占用内存 (字节)
+	GameMemoryOk bool
+	Heap         *StatusPlugin_JvmHeap
+	Disk         *disk.UsageStat
+	NetUp        float64 // 上传/下载速率 (Mbps), 监控未在采样时 NetOk 为 false
+	NetDown      float64
+	NetOk        bool
+	CommandQueue int
+	TpsAvailable bool                         // 为 false 时未找到 TPS 命令, Worlds 为空
+	Worlds       []StatusPlugin_MinecraftLoad // 按服务器输出顺序, 仅对 MSPT > 1 的世界统计实体与区块
+}
+
+// 采集当前负载, 会向服务器发送查询命令
+func (s *StatusPlugin) Snapshot() StatusPlugin_Snapshot {
 	now := time.Now()
-	s.Tellraw(`@a`, []tellraw.Message{{Text: "============ 系统负载 ============", Color: tellraw.Green}})
-	cpu_count, _ := cpu.Counts(true)
-	cpu_usage, err := cpu.Percent(0, true)
-	if err != nil {
-		log.Panic(err)
+	system := s.getSystemStatus()
+	snapshot := StatusPlugin_Snapshot{
+		Time:         now,
+		CPUCount:     system.CPUCount,
+		CPU:          system.CPUUsage,
+		CPUAvg:       system.CPUUsageAvg,
+		LoadAvg:      system.Load,
+		Disk:         system.Disk,
+		GameMemory:   system.GameMemory,
+		GameMemoryOk: system.GameMemoryOk,
+		CommandQueue: s.pm.CommandQueueDepth(),
+		TpsAvailable: s.tpsAvailable(),
+	}
+	if system.Memory != nil {
+		snapshot.MemUsed, snapshot.MemTotal = system.Memory.Used, system.Memory.Total
+	}
+	if heap, ok := s.getJvmHeap(); ok {
+		snapshot.Heap = &heap
 	}
-	if err == nil {
-		cpu_usage_avg := lo.Reduce(cpu_usage, func(agg float64, item float64, index int) float64 {
-			return agg + item
-		}, 0) / float64(len(cpu_usage)) / 100.0
-		usage_bar := int(math.RoundToEven(cpu_usage_avg * 32.0))
+	// 使用监控协程最近一次计算的速率, 不修改采样基准
+	s.netLock.Lock()
+	if last := s.lastnetStat; last != nil && last.rateOk && now.Sub(last.time) < StatusPlugin_NetRateMaxAge {
+		snapshot.NetUp, snapshot.NetDown, snapshot.NetOk = last.upSpeed, last.downSpeed, true
+	}
+	s.netLock.Unlock()
+	snapshot.Worlds = maps.Values(s.getMinecraftLoad())
+	slices.SortFunc(snapshot.Worlds, func(a StatusPlugin_MinecraftLoad, b StatusPlugin_MinecraftLoad) int {
+		return int(a.index - b.index)
+	})
+	for i, load := range snapshot.Worlds {
+		if load.MSPT > 1 {
+			snapshot.Worlds[i].EntityCount = s.getEntityCount(load.World)
+			snapshot.Worlds[i].ChunkCount = s.getChunkCount(load.World)
+		}
+	}
+	return snapshot
+}
+
+func (s *StatusPlugin) status(player string, args ...string) {
+	if len(args) > 0 && args[0] == "history" {
+		s.statusHistory(player, args[1:]...)
+		return
+	}
+	s.Tellraw(player, []tellraw.Message{{Text: "============ 系统负载 ============", Color: tellraw.Green}})
+	snapshot := s.Snapshot()
+	cpu_count, cpu_usage := snapshot.CPUCount, snapshot.CPU
+	if len(cpu_usage) > 0 {
+		cpu_usage_avg := snapshot.CPUAvg
 		per_cpu_usage := &tellraw.HoverEvent{
 			Action: tellraw.Show_Text,
 			Contents: lo.Flatten(lo.Map(cpu_usage, func(usage float64, index int) (m []tellraw.Message) {
 				if index != 0 {
 					m = append(m, tellraw.Message{Text: "\n"})
 				}
-				usage_bar := int(math.RoundToEven(usage / 100 * 32.0))
-				m = append(m, []tellraw.Message{
-					{Text: fmt.Sprintf("CPU #%d: ", index), Color: tellraw.Aqua},
-					{Text: "[", Color: tellraw.Yellow},
-					{Text: strings.Repeat("|", max(usage_bar, 0)), Color: tellraw.Red},
-					{Text: strings.Repeat("|", max(32-usage_bar, 0)), Color: tellraw.Green},
-					{Text: "]", Color: tellraw.Yellow},
-					{Text: fmt.Sprintf(" %.2f%%", usage), Color: s.floatLevel(cpu_usage_avg)},
-				}...)
-				return m
+				m = append(m, tellraw.Message{Text: fmt.Sprintf("CPU #%d: ", index), Color: tellraw.Aqua})
+				m = append(m, s.usageBar(usage/100, nil)...)
+				return append(m, tellraw.Message{Text: fmt.Sprintf(" %.2f%%", usage), Color: s.floatLevel(cpu_usage_avg)})
 			})),
 		}
-		s.Tellraw(`@a`, []tellraw.Message{
-			{Text: "CPU使用率: ", Color: tellraw.Aqua},
-			{Text: "[", Color: tellraw.Yellow},
-			{Text: strings.Repeat("|", max(usage_bar, 0)), Color: tellraw.Red, HoverEvent: per_cpu_usage},
-			{Text: strings.Repeat("|", max(32-usage_bar, 0)), Color: tellraw.Green, HoverEvent: per_cpu_usage},
-			{Text: "]", Color: tellraw.Yellow},
-			{Text: fmt.Sprintf(" %.2f%%", cpu_usage_avg*100), Color: s.floatLevel(cpu_usage_avg)},
-		})
+		msg := []tellraw.Message{{Text: "CPU使用率: ", Color: tellraw.Aqua}}
+		msg = append(msg, s.usageBar(cpu_usage_avg, per_cpu_usage)...)
+		msg = append(msg, tellraw.Message{Text: fmt.Sprintf(" %.2f%%", cpu_usage_avg*100), Color: s.floatLevel(cpu_usage_avg)})
+		s.Tellraw(player, msg)
 	}
-	system_load, err := load.Avg()
-	if err == nil && cpu_count != 0 {
-		load1, load5, load15 := system_load.Load1, system_load.Load5, system_load.Load15
-		s.Tellraw(`@a`, []tellraw.Message{
+	if snapshot.LoadAvg != nil && cpu_count != 0 {
+		load1, load5, load15 := snapshot.LoadAvg.Load1, snapshot.LoadAvg.Load5, snapshot.LoadAvg.Load15
+		s.Tellraw(player, []tellraw.Message{
 			{Text: "系统负载: ", Color: tellraw.Aqua},
 			{Text: "1min: ", Color: tellraw.Yellow},
 			{Text: fmt.Sprintf("%.2f", load1), Color: s.floatLevel(load1 / float64(cpu_count))},
@@ -305,105 +808,246 @@ func (s *StatusPlugin) status(player string, args ...string) {
 			{Text: fmt.Sprintf("%.2f", load15), Color: s.floatLevel(load15 / float64(cpu_count))},
 		})
 	}
-	sys_mem, err := mem.VirtualMemory()
-	minecraft_status, err_minecraft := s.pm.Status()
-	if err == nil && err_minecraft == nil {
-		s.Tellraw(`@a`, []tellraw.Message{
+	if snapshot.MemTotal > 0 && snapshot.GameMemoryOk {
+		memUsage := float64(snapshot.MemUsed) / float64(snapshot.MemTotal)
+		s.Tellraw(player, []tellraw.Message{
 			{Text: "内存占用: ", Color: tellraw.Aqua},
-			{Text: fmt.Sprintf("%.0f", float64(sys_mem.Used)/1024/1024), Color: s.floatLevel(float64(sys_mem.Used) / float64(sys_mem.Total))},
+			{Text: fmt.Sprintf("%.0f", float64(snapshot.MemUsed)/1024/1024), Color: s.floatLevel(memUsage)},
 			{Text: "[", Color: tellraw.Light_Purple},
-			{Text: fmt.Sprintf("%.0f", float64(minecraft_status.Usedmemory)/1024/1024), Color: s.floatLevel(float64(sys_mem.Used) / float64(sys_mem.Total))},
+			{Text: fmt.Sprintf("%.0f", float64(snapshot.GameMemory)/1024/1024), Color: s.floatLevel(memUsage)},
 			{Text: "]", Color: tellraw.Light_Purple},
 			{Text: " MiB/", Color: tellraw.Yellow},
-			{Text: fmt.Sprintf("%.0f", float64(sys_mem.Total)/1024/1024), Color: tellraw.Green},
+			{Text: fmt.Sprintf("%.0f", float64(snapshot.MemTotal)/1024/1024), Color: tellraw.Green},
 			{Text: " MiB", Color: tellraw.Yellow},
 		})
 	}
-	netio, err := s.getNetio()
-	if err == nil && s.lastnetStat != nil {
-		upSpeed := float64(netio.BytesSent-s.lastnetStat.stat.BytesSent) * 8.0 / float64(now.Sub(s.lastnetStat.time).Seconds()) / 1024.0 / 1024.0
-		downSpeed := float64(netio.BytesRecv-s.lastnetStat.stat.BytesRecv) * 8.0 / float64(now.Sub(s.lastnetStat.time).Seconds()) / 1024.0 / 1024.0
-		s.Tellraw(`@a`, []tellraw.Message{
+	if heap := snapshot.Heap; heap != nil {
+		msg := []tellraw.Message{
+			{Text: "JVM 堆: ", Color: tellraw.Aqua},
+			{Text: fmt.Sprintf("%.0f", float64(heap.Used)/1024/1024), Color: s.floatLevel(float64(heap.Used) / float64(heap.Committed))},
+			{Text: " MiB/", Color: tellraw.Yellow},
+			{Text: fmt.Sprintf("%.0f", float64(heap.Committed)/1024/1024), Color: tellraw.Green},
+			{Text: " MiB", Color: tellraw.Yellow},
+		}
+		if heap.Max > 0 {
+			msg = append(msg,
+				tellraw.Message{Text: " 最大: ", Color: tellraw.Yellow},
+				tellraw.Message{Text: fmt.Sprintf("%.0f", float64(heap.Max)/1024/1024), Color: tellraw.Green},
+				tellraw.Message{Text: " MiB", Color: tellraw.Yellow},
+			)
+		}
+		s.Tellraw(player, msg)
+	}
+	if snapshot.Disk != nil {
+		diskUsage := float64(snapshot.Disk.Used) / float64(snapshot.Disk.Total)
+		msg := []tellraw.Message{{Text: "磁盘占用: ", Color: tellraw.Aqua}}
+		msg = append(msg, s.usageBar(diskUsage, nil)...)
+		s.Tellraw(player, append(msg, []tellraw.Message{
+			{Text: fmt.Sprintf(" %.1f", float64(snapshot.Disk.Used)/1024/1024/1024), Color: s.floatLevel(diskUsage)},
+			{Text: " GiB/", Color: tellraw.Yellow},
+			{Text: fmt.Sprintf("%.1f", float64(snapshot.Disk.Total)/1024/1024/1024), Color: tellraw.Green},
+			{Text: " GiB", Color: tellraw.Yellow},
+		}...))
+	}
+	if snapshot.NetOk {
+		upSpeed, downSpeed := snapshot.NetUp, snapshot.NetDown
+		s.Tellraw(player, []tellraw.Message{
 			{Text: "网络负载: ", Color: tellraw.Aqua},
 		})
-		s.Tellraw(`@a`, []tellraw.Message{
+		s.Tellraw(player, []tellraw.Message{
 			{Text: "上传: ", Color: tellraw.Yellow},
 			{Text: fmt.Sprintf("%.2f", upSpeed), Color: s.floatLevel(upSpeed / s.MaxSentBandwidth)},
 			{Text: " Mbps", Color: tellraw.Yellow},
 			{Text: "↑", Color: tellraw.Aqua},
 			{Text: fmt.Sprintf("(%.2f%%)", upSpeed/s.MaxSentBandwidth*100), Color: s.floatLevel(upSpeed / s.MaxSentBandwidth)},
 		})
-		s.Tellraw(`@a`, []tellraw.Message{
+		s.Tellraw(player, []tellraw.Message{
 			{Text: "下载: ", Color: tellraw.Yellow},
 			{Text: fmt.Sprintf("%.2f", downSpeed), Color: s.floatLevel(downSpeed / s.MaxRecvBandwidth)},
 			{Text: " Mbps", Color: tellraw.Yellow},
 			{Text: "↓", Color: tellraw.Aqua},
 			{Text: fmt.Sprintf("(%.2f%%)", downSpeed/s.MaxRecvBandwidth*100), Color: s.floatLevel(downSpeed / s.MaxRecvBandwidth)},
 		})
-		s.lastnetStat.time = now
-		s.lastnetStat.stat = netio
 	}
-	s.Tellraw(`@a`, []tellraw.Message{{Text: "============ 服务负载 ============", Color: tellraw.Green}})
-	minecraft_load := maps.Values(s.getMinecraftLoad())
-	slices.SortFunc(minecraft_load, func(a StatusPlugin_MinecraftLoad, b StatusPlugin_MinecraftLoad) int {
-		return int(a.index - b.index)
-	})
-	for _, load := range minecraft_load {
+	s.Tellraw(player, []tellraw.Message{{Text: "============ 服务负载 ============", Color: tellraw.Green}})
+	if snapshot.CommandQueue > 0 {
+		s.Tellraw(player, []tellraw.Message{{Text: "命令队列: ", Color: tellraw.Aqua}, {Text: strconv.Itoa(snapshot.CommandQueue), Color: tellraw.Yellow}})
+	}
+	if !snapshot.TpsAvailable {
+		s.Tellraw(player, []tellraw.Message{{Text: "TPS 不可用: ", Color: tellraw.Aqua}, {Text: "未找到该服务端的 TPS 命令", Color: tellraw.Red}})
+	}
+	for _, load := range snapshot.Worlds {
 		if load.MSPT > 1 {
-			s.Tellraw(`@a`, []tellraw.Message{
+			msptHistory := s.msptSparkline(load.World)
+			msg := []tellraw.Message{
 				{Text: `世界: `, Color: tellraw.Aqua},
 				{Text: s.GetWorldName(load.World), Color: tellraw.Green, Bold: true},
-				{Text: ` TPS: `, Color: "aqua"},
+				{Text: ` TPS: `, Color: tellraw.Aqua},
 				{Text: fmt.Sprintf("%.2f", load.TPS), Color: s.msptLevel(load.MSPT)},
-				{Text: ` MSPT: `, Color: "aqua"},
-				{Text: fmt.Sprintf("%.2fms", load.MSPT), Color: s.msptLevel(load.MSPT)},
-				{Text: ` 负载: `, Color: "aqua"},
+				{Text: ` MSPT: `, Color: tellraw.Aqua},
+				{Text: fmt.Sprintf("%.2fms", load.MSPT), Color: s.msptLevel(load.MSPT), HoverEvent: msptHistory},
+				{Text: ` 负载: `, Color: tellraw.Aqua},
 				{Text: fmt.Sprintf(`%.2f%%`, load.MSPT/50*100), Color: s.msptLevel(load.MSPT)},
-			})
+			}
+			if load.EntityCount >= 0 {
+				msg = append(msg, tellraw.Message{Text: ` 实体: `, Color: tellraw.Aqua}, tellraw.Message{Text: fmt.Sprintf("%d", load.EntityCount), Color: tellraw.Yellow})
+			}
+			if load.ChunkCount >= 0 {
+				msg = append(msg, tellraw.Message{Text: ` 区块: `, Color: tellraw.Aqua}, tellraw.Message{Text: fmt.Sprintf("%d", load.ChunkCount), Color: tellraw.Yellow})
+			}
+			s.Tellraw(player, msg)
+		}
+	}
+}
+
+// 服务端类型 -> TPS 命令
+var StatusPlugin_FlavorTpsCommand = map[string]struct {
+	flavor  string
+	command string
+}{
+	pluginabi.ServerFlavorNeoForge: {StatusPlugin_FlavorForge, "neoforge tps"},
+	pluginabi.ServerFlavorForge:    {StatusPlugin_FlavorForge, "forge tps"},
+	pluginabi.ServerFlavorPaper:    {StatusPlugin_FlavorPaper, "mspt"},
+	pluginabi.ServerFlavorSpigot:   {StatusPlugin_FlavorSpigot, "tps"},
+	pluginabi.ServerFlavorVanilla:  {StatusPlugin_FlavorVanilla, "tick query"},
+	// Fabric 在 1.20.3 前没有 tick query, 需依次探测原版与 Carpet 命令
+}
+
+func (s *StatusPlugin) detectTPSCommand() {
+	info := s.pm.ServerInfo()
+	tps, ok := StatusPlugin_FlavorTpsCommand[info.Flavor]
+	if !ok {
+		if !s.testTPSCommand() {
+			s.Println(color.RedString("未找到可用的 TPS 命令, 世界负载监控已停用, 每 "), color.MagentaString("%s", s.TpsRetryInterval), color.RedString(" 重新检测"))
 		}
+		return
 	}
+	s.setTPSCommand(tps.command, tps.flavor)
+	s.Println(color.YellowString("服务端类型: "), color.GreenString(info.Flavor), color.YellowString(" TPS 命令: "), color.GreenString(tps.command))
+}
+
+func (s *StatusPlugin) setTPSCommand(command string, flavor string) {
+	s.tpsLock.Lock()
+	s.ForgeTpsCommand = command
+	s.ServerFlavor = flavor
+	s.tpsLock.Unlock()
 }
 
-func (s *StatusPlugin) testTPSCommand() {
-	tpsCommands := []string{"neoforge tps", "forge tps"}
+func (s *StatusPlugin) testTPSCommand() bool {
+	// mspt 为 Paper 独有命令, 需在 tps 之前探测
+	tpsCommands := []struct {
+		flavor  string
+		command string
+	}{
+		{StatusPlugin_FlavorForge, "neoforge tps"},
+		{StatusPlugin_FlavorForge, "forge tps"},
+		{StatusPlugin_FlavorPaper, "mspt"},
+		{StatusPlugin_FlavorSpigot, "tps"},
+		{StatusPlugin_FlavorVanilla, "tick query"},
+		{StatusPlugin_FlavorCarpet, StatusPlugin_CarpetTpsCommand},
+	}
 	for _, testcmd := range tpsCommands {
-		res := s.RunCommand(testcmd)
-		if !core.UnknownCommand.MatchString(res) {
-			s.ForgeTpsCommand = testcmd
-			return
+		res := s.RunCommand(testcmd.command)
+		if res != "" && !core.UnknownCommand.MatchString(res) {
+			// 未安装 Carpet 时 script 可能被其他模组占用, 以能否解析结果为准
+			if testcmd.flavor == StatusPlugin_FlavorCarpet && !StatusPlugin_ParseCarpetMspt.MatchString(StatusPlugin_ColorCode.ReplaceAllString(res, "")) {
+				continue
+			}
+			s.setTPSCommand(testcmd.command, testcmd.flavor)
+			s.Println(color.YellowString("检测到服务端类型: "), color.GreenString(testcmd.flavor), color.YellowString(" TPS 命令: "), color.GreenString(testcmd.command))
+			return true
 		}
 	}
+	return false
 }
 
-func (s *StatusPlugin) monitorWorker() {
-	monitorTicker := time.NewTicker(10 * time.Second)
+// 根据最近的 MSPT 趋势调整采样间隔: 上升时减半, 平稳时逐步延长
+func (s *StatusPlugin) nextMonitorInterval(interval time.Duration) time.Duration {
+	if len(s.LastMspt) < 2 {
+		return interval
+	}
+	K := s.leastsquares(s.LastMspt)
+	latest := s.LastMspt[len(s.LastMspt)-1]
+	switch {
+	case K > s.AlertSlope/2 || latest >= s.MsptThreshold.Yellow:
+		interval /= 2
+	case math.Abs(K) < s.AlertSlope/4:
+		interval = interval * 3 / 2
+	}
+	return min(max(interval, s.MonitorMinInterval), s.MonitorMaxInterval)
+}
+
+func (s *StatusPlugin) monitorWorker(stop chan struct{}) {
+	// 每次启动从最长间隔开始
+	monitorInterval := s.MonitorMaxInterval
+	monitorTicker := time.NewTicker(monitorInterval)
 	systemTicker := time.NewTicker(1 * time.Second)
-	s.monitorStop = make(chan struct{}, 1)
+	tpsRetryTicker := time.NewTicker(s.TpsRetryInterval)
+	defer monitorTicker.Stop()
+	defer systemTicker.Stop()
+	defer tpsRetryTicker.Stop()
 	for {
 		select {
+		case <-tpsRetryTicker.C:
+			// 模组可能在服务器启动后才注册 TPS 命令
+			if !s.tpsAvailable() {
+				s.testTPSCommand()
+			}
 		case <-monitorTicker.C:
-			if len(s.GetPlayerList()) > 0 {
+			if len(s.GetPlayerList()) > 0 || s.exporter.Load() != nil {
 				s.monitorGame()
+				if interval := s.nextMonitorInterval(monitorInterval); interval != monitorInterval {
+					monitorInterval = interval
+					monitorTicker.Reset(interval)
+				}
 			}
 		case <-systemTicker.C:
-			if len(s.GetPlayerList()) > 0 {
+			if len(s.GetPlayerList()) > 0 || s.exporter.Load() != nil {
 				s.monitorSystem()
 			}
-		case <-s.monitorStop:
+		case <-stop:
 			return
 		}
 	}
 }
 
 func (s *StatusPlugin) Start() {
-	if s.ForgeTpsCommand == "" {
-		s.testTPSCommand()
+	s.historyLock.Lock()
+	s.history = make(map[string][]StatusPlugin_LoadSample)
+	s.historyLock.Unlock()
+	s.LastMspt = nil
+	if command, flavor := s.tpsCommand(); command == "" {
+		s.detectTPSCommand()
+	} else if flavor == "" {
+		s.setTPSCommand(command, StatusPlugin_FlavorForge)
+	}
+	if s.MetricsListen != "" && s.exporter.Load() == nil {
+		exporter := newStatusExporter(s.MetricsListen)
+		err := exporter.start()
+		if err != nil {
+			s.Println(color.RedString("启动 Prometheus 导出失败: "), color.MagentaString(err.Error()))
+		} else {
+			s.exporter.Store(exporter)
+			s.Println(color.YellowString("Prometheus 导出已启动: "), color.GreenString("http://%s/metrics", s.MetricsListen))
+		}
 	}
-	go s.monitorWorker()
+	s.Println(color.YellowString("负载告警: 斜率 > "), color.GreenString("%.2f", s.AlertSlope), color.YellowString(" MSPT 变化 > "), color.GreenString("%.2fms", s.AlertDelta), color.YellowString(" 间隔 >= "), color.GreenString("%s", s.AlertInterval))
+	// 重复 Start 时先停止旧的 monitorWorker
+	s.stopMonitor()
+	s.monitorStop = make(chan struct{})
+	go s.monitorWorker(s.monitorStop)
 }
 
-func (s *StatusPlugin) Pause() {
+func (s *StatusPlugin) stopMonitor() {
 	if s.monitorStop != nil {
-		s.monitorStop <- struct{}{}
+		close(s.monitorStop)
+		s.monitorStop = nil
+	}
+}
+
+func (s *StatusPlugin) Pause() {
+	s.stopMonitor()
+	if exporter := s.exporter.Swap(nil); exporter != nil {
+		exporter.stop()
 	}
 }
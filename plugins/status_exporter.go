@@ -0,0 +1,119 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type StatusPlugin_Exporter struct {
+	registry   *prometheus.Registry
+	server     *http.Server
+	mspt       *prometheus.GaugeVec
+	tps        *prometheus.GaugeVec
+	cpuUsage   prometheus.Gauge
+	systemLoad *prometheus.GaugeVec
+	memUsed    prometheus.Gauge
+	memTotal   prometheus.Gauge
+	gameMemory prometheus.Gauge
+}
+
+func newStatusExporter(listen string) *StatusPlugin_Exporter {
+	e := &StatusPlugin_Exporter{
+		registry: prometheus.NewRegistry(),
+		mspt: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "minecraft_mspt_milliseconds",
+			Help: "Mean tick time per world",
+		}, []string{"world"}),
+		tps: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "minecraft_tps",
+			Help: "Ticks per second per world",
+		}, []string{"world"}),
+		cpuUsage: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "system_cpu_usage_ratio",
+			Help: "Average CPU usage across all cores",
+		}),
+		systemLoad: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "system_load_average",
+			Help: "System load average",
+		}, []string{"period"}),
+		memUsed: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "system_memory_used_bytes",
+			Help: "Used system memory",
+		}),
+		memTotal: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "system_memory_total_bytes",
+			Help: "Total system memory",
+		}),
+		gameMemory: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "minecraft_memory_used_bytes",
+			Help: "Memory used by the Minecraft server process",
+		}),
+	}
+	e.registry.MustRegister(e.mspt, e.tps, e.cpuUsage, e.systemLoad, e.memUsed, e.memTotal, e.gameMemory)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{}))
+	e.server = &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return e
+}
+
+func (e *StatusPlugin_Exporter) start() error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.server.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(100 * time.Millisecond):
+		return nil
+	}
+}
+
+func (e *StatusPlugin_Exporter) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := e.server.Shutdown(ctx)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		e.server.Close()
+	}
+}
+
+func (e *StatusPlugin_Exporter) updateLoad(load map[string]StatusPlugin_MinecraftLoad) {
+	for world, worldLoad := range load {
+		e.mspt.WithLabelValues(world).Set(worldLoad.MSPT)
+		e.tps.WithLabelValues(world).Set(worldLoad.TPS)
+	}
+}
+
+func (e *StatusPlugin_Exporter) updateSystem(system *StatusPlugin_SystemStatus) {
+	e.cpuUsage.Set(system.CPUUsageAvg)
+	if system.Load != nil {
+		e.systemLoad.WithLabelValues("1m").Set(system.Load.Load1)
+		e.systemLoad.WithLabelValues("5m").Set(system.Load.Load5)
+		e.systemLoad.WithLabelValues("15m").Set(system.Load.Load15)
+	}
+	if system.Memory != nil {
+		e.memUsed.Set(float64(system.Memory.Used))
+		e.memTotal.Set(float64(system.Memory.Total))
+	}
+	e.gameMemory.Set(float64(system.GameMemory))
+}
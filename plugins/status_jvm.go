@@ -0,0 +1,73 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// 需要以 -Xlog:gc (或 -Xlog:gc*) 启动服务器
+var StatusPlugin_GCHeapMessage = regexp.MustCompile(`\[gc\s*\].*?(\d+)([KMG])->(\d+)([KMG])\((\d+)([KMG])\)`)
+var StatusPlugin_GCMaxHeapMessage = regexp.MustCompile(`Heap Max Capacity: (\d+)([KMG])`)
+
+type StatusPlugin_JvmHeap struct {
+	Used      uint64
+	Committed uint64
+	Max       uint64
+	Time      time.Time
+}
+
+func parseJvmSize(value string, unit string) uint64 {
+	size, _ := strconv.ParseUint(value, 10, 64)
+	switch unit {
+	case "K":
+		return size << 10
+	case "M":
+		return size << 20
+	case "G":
+		return size << 30
+	}
+	return size
+}
+
+func (s *StatusPlugin) gcLogProcesser(log string, _ bool) {
+	if match := StatusPlugin_GCMaxHeapMessage.FindStringSubmatch(log); match != nil {
+		s.heapLock.Lock()
+		s.heap.Max = parseJvmSize(match[1], match[2])
+		s.heapLock.Unlock()
+		return
+	}
+	match := StatusPlugin_GCHeapMessage.FindStringSubmatch(log)
+	if match == nil {
+		return
+	}
+	s.heapLock.Lock()
+	s.heap.Used = parseJvmSize(match[3], match[4])
+	s.heap.Committed = parseJvmSize(match[5], match[6])
+	s.heap.Time = time.Now()
+	s.heapLock.Unlock()
+}
+
+// 最近 10 分钟内没有 GC 日志时视为不可用
+func (s *StatusPlugin) getJvmHeap() (StatusPlugin_JvmHeap, bool) {
+	s.heapLock.RLock()
+	defer s.heapLock.RUnlock()
+	if s.heap.Time.IsZero() || time.Since(s.heap.Time) > 10*time.Minute {
+		return StatusPlugin_JvmHeap{}, false
+	}
+	return s.heap, true
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"cgit.bbaa.fun/bbaa/minecraft-plugin-daemon/core/plugin/tellraw"
+)
+
+func TestLeastSquaresShortSeries(t *testing.T) {
+	s := &StatusPlugin{}
+	cases := []struct {
+		series []float64
+		slope  float64
+	}{
+		{nil, 0},
+		{[]float64{50}, 0},
+		{[]float64{50, 60}, 10},
+		{[]float64{40, 40, 40}, 0},
+	}
+	for _, c := range cases {
+		K := s.leastsquares(c.series)
+		if math.IsNaN(K) || math.IsInf(K, 0) || math.Abs(K-c.slope) > 1e-9 {
+			t.Errorf("leastsquares(%v) = %v, 应为 %v", c.series, K, c.slope)
+		}
+	}
+}
+
+func TestMsptSparklineShortSeries(t *testing.T) {
+	s := &StatusPlugin{MsptThreshold: StatusPlugin_Threshold{Yellow: 55, Red: 65}}
+	now := time.Now()
+	s.history = map[string][]StatusPlugin_LoadSample{"Overall": {{Time: now, MSPT: 50, TPS: 20}}}
+	if hover := s.msptSparkline("Overall"); hover != nil {
+		t.Errorf("单个样本不应生成趋势图: %+v", hover)
+	}
+	s.history["Overall"] = append(s.history["Overall"], StatusPlugin_LoadSample{Time: now.Add(time.Second), MSPT: 50, TPS: 20})
+	hover := s.msptSparkline("Overall")
+	if hover == nil {
+		t.Fatal("两个样本未生成趋势图")
+	}
+	// 标题两段后为每个样本一个字符, 样本相同时取最低一级
+	if len(hover.Contents.([]tellraw.Message)) != 4 {
+		t.Errorf("趋势图长度错误: %+v", hover.Contents)
+	}
+	for _, msg := range hover.Contents.([]tellraw.Message)[2:] {
+		if msg.Text != string(StatusPlugin_SparklineChar[0]) {
+			t.Errorf("相同样本的趋势图应为最低一级: %q", msg.Text)
+		}
+	}
+}
+
+func TestStatusPauseWithoutStart(t *testing.T) {
+	s := &StatusPlugin{}
+	s.Pause()
+	s.Pause()
+}
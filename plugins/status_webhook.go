@@ -0,0 +1,93 @@
+// Copyright 2024 bbaa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+type StatusPlugin_WebhookField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type StatusPlugin_WebhookEmbed struct {
+	Title     string                      `json:"title"`
+	Color     int                         `json:"color"`
+	Fields    []StatusPlugin_WebhookField `json:"fields"`
+	Timestamp string                      `json:"timestamp"`
+}
+
+type StatusPlugin_WebhookPayload struct {
+	Content string                      `json:"content"`
+	Embeds  []StatusPlugin_WebhookEmbed `json:"embeds"`
+}
+
+var statusWebhookClient = &http.Client{Timeout: 10 * time.Second}
+
+func (s *StatusPlugin) sendWebhookAlert(title string, increase bool, world string, load StatusPlugin_MinecraftLoad, slope float64) {
+	if s.AlertWebhook == "" {
+		return
+	}
+	now := time.Now()
+	s.webhookLock.Lock()
+	if now.Sub(s.lastWebhook) < s.AlertWebhookCooldown {
+		s.webhookLock.Unlock()
+		return
+	}
+	s.lastWebhook = now
+	s.webhookLock.Unlock()
+	embedColor := 0x55ff55
+	if increase {
+		embedColor = 0xff5555
+	}
+	payload := StatusPlugin_WebhookPayload{
+		Content: title,
+		Embeds: []StatusPlugin_WebhookEmbed{{
+			Title: title,
+			Color: embedColor,
+			Fields: []StatusPlugin_WebhookField{
+				{Name: "World", Value: world, Inline: true},
+				{Name: "TPS", Value: fmt.Sprintf("%.2f", load.TPS), Inline: true},
+				{Name: "MSPT", Value: fmt.Sprintf("%.2fms", load.MSPT), Inline: true},
+				{Name: "Slope", Value: fmt.Sprintf("%.2f", slope), Inline: true},
+			},
+			Timestamp: now.Format(time.RFC3339),
+		}},
+	}
+	// 在独立的 goroutine 中发送, 避免阻塞 monitorWorker
+	go func() {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return
+		}
+		resp, err := statusWebhookClient.Post(s.AlertWebhook, "application/json", bytes.NewReader(body))
+		if err != nil {
+			s.Println(color.RedString("发送 Webhook 告警失败: "), color.MagentaString(err.Error()))
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			s.Println(color.RedString("发送 Webhook 告警失败: "), color.MagentaString(resp.Status))
+		}
+	}()
+}
@@ -45,6 +45,12 @@ func (tp *TeleportPlugin) Init(pm pluginabi.PluginManager) (err error) {
 	if err != nil {
 		return err
 	}
+	tp.RegisterCommandCompleter("tp", func(args []string) []string {
+		if len(args) > 1 {
+			return nil
+		}
+		return tp.GetPlayerList()
+	})
 	return nil
 }
 